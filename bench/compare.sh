@@ -0,0 +1,31 @@
+#!/bin/sh
+# Runs the handler benchmarks and compares them to bench/baseline.txt with benchstat.
+# Fails when the time or the allocations of a benchmark regress by more than 20%.
+# Regenerate the baseline by running it with UPDATE_BASELINE=1.
+set -eu
+
+cd "$(dirname "$0")/.."
+benchmarks='GetWeatherHandler_|PostWeatherHandler'
+threshold=20
+
+go test ./handler -run '^$' -bench "$benchmarks" -benchmem -count=5 2>/dev/null |
+	grep -E '^(goos|goarch|pkg|cpu|Benchmark)' >bench/new.txt
+
+if [ "${UPDATE_BASELINE:-}" = 1 ]; then
+	mv bench/new.txt bench/baseline.txt
+	exit 0
+fi
+
+go run golang.org/x/perf/cmd/benchstat@latest bench/baseline.txt bench/new.txt | tee bench/benchstat.txt
+
+# Only the significant deltas are reported by benchstat, as +N.NN%.
+awk -v threshold="$threshold" '
+	match($0, /\+[0-9.]+%/) {
+		delta = substr($0, RSTART + 1, RLENGTH - 2) + 0
+		if (delta > threshold) {
+			print "regression over " threshold "%: " $0
+			failed = 1
+		}
+	}
+	END { exit failed }
+' bench/benchstat.txt
@@ -0,0 +1,288 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/leoseiji/go-tracing/dnscache"
+	"github.com/leoseiji/go-tracing/handler"
+	"github.com/leoseiji/go-tracing/middleware"
+	"github.com/leoseiji/go-tracing/otel"
+)
+
+const (
+	// DefaultHTTPPort, DefaultGRPCPort and DefaultPprofPort are the ports of the servers when PORT, GRPC_PORT and PPROF_PORT are unset.
+	DefaultHTTPPort  = "8080"
+	DefaultGRPCPort  = "9090"
+	DefaultPprofPort = "6060"
+
+	// TransportHTTP and TransportGRPC are the values accepted by SERVICE_B_TRANSPORT.
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+// Config holds the settings of both services, read once at startup by Load.
+type Config struct {
+	// OTel is the OpenTelemetry pipeline, see otel.LoadConfig.
+	OTel otel.Config
+	// ShutdownTimeout bounds flushing the telemetry on shutdown.
+	ShutdownTimeout time.Duration
+	// LogLevel is the minimum level of the logs.
+	LogLevel slog.Level
+
+	HTTPPort  string
+	GRPCPort  string
+	PprofPort string
+	// EnablePprof serves net/http/pprof on PprofPort.
+	EnablePprof bool
+	// SkipStartupProbe starts without checking the upstreams first.
+	SkipStartupProbe bool
+
+	// ServiceBTransport is how service A calls service B: TransportHTTP or TransportGRPC.
+	ServiceBTransport string
+	// ServiceBURL is the base URL of service B for TransportHTTP.
+	ServiceBURL string
+	// ServiceBGRPCTarget is the address of service B for TransportGRPC, over TLS when ServiceBGRPCTLS is set.
+	ServiceBGRPCTarget string
+	ServiceBGRPCTLS    bool
+	// DisableHTTP2 keeps the HTTP calls to service B on HTTP/1.1.
+	DisableHTTP2 bool
+	// InternalSecret authenticates the requests of service A to service B,
+	// signed less than InternalSignatureSkew away. Service B is open to any caller when it is empty.
+	InternalSecret        []byte
+	InternalSignatureSkew time.Duration
+	BatchMaxConcurrency   int
+
+	AllowedOrigins      []string
+	MaxRequestBodyBytes int64
+	RequestTimeout      time.Duration
+	GzipMinSize         int
+	// RateLimitRPS requests are admitted per second, in bursts of up to RateLimitBurst. 0 does not limit them.
+	RateLimitRPS   float64
+	RateLimitBurst int
+	// AccessLogSampleRate is the fraction of the requests logged, from none at 0 to all of them at 1.
+	AccessLogSampleRate float64
+	// AdminToken authenticates the operators on the admin endpoints, which are disabled when it is empty.
+	AdminToken string
+
+	ViaCEPBaseURL     string
+	APICEPBaseURL     string
+	WeatherAPIBaseURL string
+	// WeatherAPIKey authenticates the lookups on WeatherAPI. It is required.
+	WeatherAPIKey string
+	// TLSCABundle is a PEM file of the CAs of the upstreams, trusted instead of the system pool.
+	TLSCABundle string
+	// InsecureSkipTLSVerify skips verifying the certificates of the upstreams, for local development only.
+	InsecureSkipTLSVerify bool
+	ConnectTimeout        time.Duration
+	ReadTimeout           time.Duration
+	DNSCacheTTL           time.Duration
+	CEPCacheTTL           time.Duration
+	CEPCacheMaxEntries    int
+	SlowQueryThreshold    time.Duration
+	// DeadLetterLogFile receives the failed upstream calls, which are logged on stderr when it is empty.
+	DeadLetterLogFile string
+}
+
+// Load reads the settings from the environment variables:
+//
+//	LOG_LEVEL                   debug, info (default), warn or error
+//	SHUTDOWN_TIMEOUT_MS         flushing the telemetry on shutdown (default 5s)
+//	PORT, GRPC_PORT             ports of the HTTP (default 8080) and gRPC (default 9090) servers
+//	ENABLE_PPROF, PPROF_PORT    serve net/http/pprof on its own port (default 6060)
+//	SKIP_STARTUP_PROBE          start without checking the upstreams
+//	SERVICE_B_TRANSPORT         http (default) or grpc
+//	SERVICE_B_URL               base URL of service B (default http://localhost:8080)
+//	SERVICE_B_GRPC_TARGET       address of the gRPC server of service B, over TLS with SERVICE_B_GRPC_TLS
+//	DISABLE_HTTP2               call service B over HTTP/1.1
+//	INTERNAL_SECRET             signs the calls to service B, see INTERNAL_SIGNATURE_SKEW_MS (default 30s)
+//	BATCH_MAX_CONCURRENCY       concurrent service B calls of a batch lookup (default 10)
+//	CORS_ALLOWED_ORIGINS        origins of browser applications, comma-separated (default *)
+//	MAX_REQUEST_BODY_BYTES      bound of the request bodies (default 1 MB)
+//	REQUEST_TIMEOUT_MS          bound of serving a request, answered 503 past it (default 9s)
+//	GZIP_MIN_SIZE_BYTES         size from which responses are compressed (default 1 KB)
+//	RATE_LIMIT_RPS              requests admitted per second (default unlimited), in bursts of RATE_LIMIT_BURST (default 1)
+//	ACCESS_LOG_SAMPLE_RATE      fraction of the requests logged, between 0 (none) and 1 (all, the default)
+//	ADMIN_TOKEN                 enables the admin endpoints
+//	VIACEP_BASE_URL, APICEP_BASE_URL, WEATHER_API_BASE_URL
+//	                            base URLs of the upstreams, to use stubs or staging environments
+//	WEATHER_API_KEY             key of WeatherAPI, required
+//	TLS_CA_BUNDLE               CAs of the upstreams, INSECURE_SKIP_TLS_VERIFY is refused in production
+//	CONNECT_TIMEOUT_MS, READ_TIMEOUT_MS
+//	                            bounds of connecting to the upstreams (default 3s) and of their responses (default 5s)
+//	DNS_CACHE_TTL_S             caching of the addresses of the upstreams (default 60s)
+//	CEP_CACHE_TTL_MS            caching of the locations of the CEPs (default 24h)
+//	CEP_CACHE_MAX_ENTRIES       CEPs cached, the least recently used are evicted (default 10000)
+//	SLOW_QUERY_THRESHOLD_MS     duration from which lookups are logged as slow
+//	DEAD_LETTER_LOG_FILE        file receiving the failed upstream calls (default stderr)
+//
+// and the OpenTelemetry settings with otel.LoadConfig.
+// Unset variables take their default. Every invalid or missing required variable is reported in the error.
+func Load() (*Config, error) {
+	otelConfig, err := otel.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	var env envReader
+	cfg := &Config{
+		OTel:            otelConfig,
+		ShutdownTimeout: env.milliseconds("SHUTDOWN_TIMEOUT_MS", otel.DefaultShutdownTimeout),
+		LogLevel:        env.logLevel("LOG_LEVEL"),
+
+		HTTPPort:         env.port("PORT", DefaultHTTPPort),
+		GRPCPort:         env.port("GRPC_PORT", DefaultGRPCPort),
+		PprofPort:        env.port("PPROF_PORT", DefaultPprofPort),
+		EnablePprof:      env.bool("ENABLE_PPROF"),
+		SkipStartupProbe: env.bool("SKIP_STARTUP_PROBE"),
+
+		ServiceBTransport:     env.string("SERVICE_B_TRANSPORT", TransportHTTP),
+		ServiceBURL:           env.string("SERVICE_B_URL", handler.DefaultServiceBURL),
+		ServiceBGRPCTarget:    os.Getenv("SERVICE_B_GRPC_TARGET"),
+		ServiceBGRPCTLS:       env.bool("SERVICE_B_GRPC_TLS"),
+		DisableHTTP2:          env.bool("DISABLE_HTTP2"),
+		InternalSecret:        []byte(os.Getenv("INTERNAL_SECRET")),
+		InternalSignatureSkew: env.milliseconds("INTERNAL_SIGNATURE_SKEW_MS", middleware.DefaultSignatureSkew),
+		BatchMaxConcurrency:   env.int("BATCH_MAX_CONCURRENCY", handler.DefaultBatchMaxConcurrency),
+
+		AllowedOrigins:      middleware.ParseAllowedOrigins(os.Getenv("CORS_ALLOWED_ORIGINS")),
+		MaxRequestBodyBytes: int64(env.int("MAX_REQUEST_BODY_BYTES", middleware.DefaultMaxRequestBodyBytes)),
+		RequestTimeout:      env.milliseconds("REQUEST_TIMEOUT_MS", middleware.DefaultRequestTimeout),
+		GzipMinSize:         env.int("GZIP_MIN_SIZE_BYTES", middleware.DefaultGzipMinSize),
+		RateLimitRPS:        env.float("RATE_LIMIT_RPS", 0, 0),
+		RateLimitBurst:      env.int("RATE_LIMIT_BURST", 1),
+		AccessLogSampleRate: env.float("ACCESS_LOG_SAMPLE_RATE", 1, 1),
+		AdminToken:          os.Getenv("ADMIN_TOKEN"),
+
+		ViaCEPBaseURL:         os.Getenv("VIACEP_BASE_URL"),
+		APICEPBaseURL:         os.Getenv("APICEP_BASE_URL"),
+		WeatherAPIBaseURL:     os.Getenv("WEATHER_API_BASE_URL"),
+		WeatherAPIKey:         env.required("WEATHER_API_KEY"),
+		TLSCABundle:           os.Getenv("TLS_CA_BUNDLE"),
+		InsecureSkipTLSVerify: env.bool("INSECURE_SKIP_TLS_VERIFY"),
+		ConnectTimeout:        env.milliseconds("CONNECT_TIMEOUT_MS", handler.DefaultConnectTimeout),
+		ReadTimeout:           env.milliseconds("READ_TIMEOUT_MS", handler.DefaultReadTimeout),
+		DNSCacheTTL:           time.Duration(env.int("DNS_CACHE_TTL_S", int(dnscache.DefaultTTL/time.Second))) * time.Second,
+		CEPCacheTTL:           env.milliseconds("CEP_CACHE_TTL_MS", handler.DefaultCEPCacheTTL),
+		CEPCacheMaxEntries:    env.int("CEP_CACHE_MAX_ENTRIES", handler.DefaultCEPCacheMaxEntries),
+		SlowQueryThreshold:    env.milliseconds("SLOW_QUERY_THRESHOLD_MS", handler.DefaultSlowQueryThreshold),
+		DeadLetterLogFile:     os.Getenv("DEAD_LETTER_LOG_FILE"),
+	}
+
+	switch cfg.ServiceBTransport {
+	case TransportHTTP, TransportGRPC:
+	default:
+		env.fail("invalid SERVICE_B_TRANSPORT %q: expected %s or %s", cfg.ServiceBTransport, TransportHTTP, TransportGRPC)
+	}
+	if cfg.InsecureSkipTLSVerify && cfg.OTel.Environment == "production" {
+		env.fail("INSECURE_SKIP_TLS_VERIFY must not be set when ENVIRONMENT=production")
+	}
+	if err := errors.Join(env.errs...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// envReader reads environment variables, collecting the errors of the invalid ones.
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) fail(format string, args ...any) {
+	e.errs = append(e.errs, fmt.Errorf(format, args...))
+}
+
+// string reads key, returning fallback when it is unset or empty.
+func (e *envReader) string(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// required reads key, failing when it is unset or empty.
+func (e *envReader) required(key string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		e.fail("missing %s: it is required", key)
+	}
+	return value
+}
+
+// bool reads key, returning false when it is unset.
+func (e *envReader) bool(key string) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		e.fail("invalid %s %q: expected true or false", key, value)
+	}
+	return b
+}
+
+// int reads a positive integer from key, returning fallback when it is unset.
+func (e *envReader) int(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		e.fail("invalid %s %q: expected a positive integer", key, value)
+		return fallback
+	}
+	return n
+}
+
+// float reads a number from key, from 0 to max unless max is 0.
+// fallback is returned when it is unset.
+func (e *envReader) float(key string, fallback, max float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	switch {
+	case err != nil || !(f >= 0):
+		e.fail("invalid %s %q: expected a non-negative number", key, value)
+	case max > 0 && f > max:
+		e.fail("invalid %s %q: expected a number between 0 and %g", key, value, max)
+	default:
+		return f
+	}
+	return fallback
+}
+
+// milliseconds reads a duration in milliseconds from key, returning fallback when it is unset.
+func (e *envReader) milliseconds(key string, fallback time.Duration) time.Duration {
+	return time.Duration(e.int(key, int(fallback/time.Millisecond))) * time.Millisecond
+}
+
+// port reads a TCP port from key, returning fallback when it is unset.
+func (e *envReader) port(key, fallback string) string {
+	value := e.string(key, fallback)
+	if n, err := strconv.Atoi(value); err != nil || n <= 0 || n > 65535 {
+		e.fail("invalid %s %q: expected a port between 1 and 65535", key, value)
+		return fallback
+	}
+	return value
+}
+
+// logLevel reads a slog level from key, returning slog.LevelInfo when it is unset.
+func (e *envReader) logLevel(key string) slog.Level {
+	value := os.Getenv(key)
+	if value == "" {
+		return slog.LevelInfo
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		e.fail("invalid %s %q: expected debug, info, warn or error", key, value)
+		return slog.LevelInfo
+	}
+	return level
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/leoseiji/go-tracing/handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadDefaults(t *testing.T) {
+	t.Setenv("WEATHER_API_KEY", "test")
+
+	cfg, err := Load()
+
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "test", cfg.WeatherAPIKey)
+	assert.Equal(t, handler.DefaultServiceBURL, cfg.ServiceBURL)
+	assert.Equal(t, TransportHTTP, cfg.ServiceBTransport)
+	assert.Equal(t, DefaultHTTPPort, cfg.HTTPPort)
+	assert.Equal(t, slog.LevelInfo, cfg.LogLevel)
+	assert.Equal(t, handler.DefaultReadTimeout, cfg.ReadTimeout)
+	assert.Equal(t, time.Minute, cfg.DNSCacheTTL)
+	assert.Equal(t, 1.0, cfg.AccessLogSampleRate)
+	assert.Zero(t, cfg.RateLimitRPS)
+}
+
+func TestLoadWeatherAPIBaseURL(t *testing.T) {
+	var requestURI string
+	weatherAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+		w.Write([]byte(`{"current": {"temp_c": 25.0}}`))
+	}))
+	defer weatherAPI.Close()
+	t.Setenv("WEATHER_API_KEY", "test")
+	t.Setenv("WEATHER_API_BASE_URL", weatherAPI.URL)
+
+	cfg, err := Load()
+	if !assert.NoError(t, err) {
+		return
+	}
+	// As main creates the provider.
+	provider := handler.NewWeatherAPIProvider(handler.WeatherAPIConfig{BaseURL: cfg.WeatherAPIBaseURL, APIKey: cfg.WeatherAPIKey})
+	_, err = provider.GetWeather(context.Background(), "Osasco")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/v1/current.json?key=test&q=Osasco", requestURI)
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "Valid values", env: map[string]string{"SERVICE_B_URL": "http://service-b:8080", "SERVICE_B_TRANSPORT": "grpc", "LOG_LEVEL": "debug", "READ_TIMEOUT_MS": "2000", "ACCESS_LOG_SAMPLE_RATE": "0.5", "ENABLE_PPROF": "true"}},
+		{name: "Missing WEATHER_API_KEY", env: map[string]string{"WEATHER_API_KEY": ""}, wantErr: "missing WEATHER_API_KEY"},
+		{name: "Invalid integer", env: map[string]string{"BATCH_MAX_CONCURRENCY": "ten"}, wantErr: `invalid BATCH_MAX_CONCURRENCY "ten"`},
+		{name: "Negative duration", env: map[string]string{"READ_TIMEOUT_MS": "-1"}, wantErr: `invalid READ_TIMEOUT_MS "-1"`},
+		{name: "Invalid boolean", env: map[string]string{"ENABLE_PPROF": "yes please"}, wantErr: `invalid ENABLE_PPROF "yes please"`},
+		{name: "Invalid port", env: map[string]string{"PORT": "80800"}, wantErr: `invalid PORT "80800"`},
+		{name: "Invalid log level", env: map[string]string{"LOG_LEVEL": "verbose"}, wantErr: `invalid LOG_LEVEL "verbose"`},
+		{name: "Invalid transport", env: map[string]string{"SERVICE_B_TRANSPORT": "websocket"}, wantErr: `invalid SERVICE_B_TRANSPORT "websocket"`},
+		{name: "Sample rate above 1", env: map[string]string{"ACCESS_LOG_SAMPLE_RATE": "1.5"}, wantErr: `invalid ACCESS_LOG_SAMPLE_RATE "1.5"`},
+		{name: "Sample rate of 0", env: map[string]string{"ACCESS_LOG_SAMPLE_RATE": "0"}},
+		{name: "Negative sample rate", env: map[string]string{"ACCESS_LOG_SAMPLE_RATE": "-0.5"}, wantErr: `invalid ACCESS_LOG_SAMPLE_RATE "-0.5"`},
+		{name: "Unlimited rate", env: map[string]string{"RATE_LIMIT_RPS": "0"}},
+		{name: "Negative rate", env: map[string]string{"RATE_LIMIT_RPS": "-1"}, wantErr: `invalid RATE_LIMIT_RPS "-1"`},
+		{name: "Insecure TLS in production", env: map[string]string{"INSECURE_SKIP_TLS_VERIFY": "true", "ENVIRONMENT": "production"}, wantErr: "INSECURE_SKIP_TLS_VERIFY must not be set"},
+		{name: "Invalid OTel setting", env: map[string]string{"OTEL_EXPORTER": "kafka"}, wantErr: "OTEL_EXPORTER"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WEATHER_API_KEY", "test")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := Load()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Nil(t, cfg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLoadReportsEveryInvalidVariable(t *testing.T) {
+	t.Setenv("WEATHER_API_KEY", "")
+	t.Setenv("GZIP_MIN_SIZE_BYTES", "1KB")
+
+	_, err := Load()
+
+	assert.ErrorContains(t, err, "missing WEATHER_API_KEY")
+	assert.ErrorContains(t, err, `invalid GZIP_MIN_SIZE_BYTES "1KB"`)
+}
@@ -0,0 +1,111 @@
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long resolved addresses are kept when no TTL is given.
+const DefaultTTL = time.Minute
+
+// Resolver looks up the addresses of a host. *net.Resolver implements it.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) (addrs []string, err error)
+}
+
+type entry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// Cache is a Resolver that keeps the addresses returned by another Resolver for a TTL.
+// Go's resolver does not expose the TTL of the DNS records it returns,
+// so every entry lives for the same, configured TTL.
+type Cache struct {
+	resolver Resolver
+	ttl      time.Duration
+	dialer   *net.Dialer
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+// New creates a Cache in front of resolver.
+// A nil resolver falls back to net.DefaultResolver and a non-positive ttl to DefaultTTL.
+func New(resolver Resolver, ttl time.Duration) *Cache {
+	return NewWithDialer(resolver, ttl, nil)
+}
+
+// NewWithDialer creates a Cache in front of resolver, whose DialContext connects through dialer.
+// A nil dialer times out after 30s, like the dialer of http.DefaultTransport.
+func NewWithDialer(resolver Resolver, ttl time.Duration, dialer *net.Dialer) *Cache {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if dialer == nil {
+		dialer = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	}
+	return &Cache{
+		resolver: resolver,
+		ttl:      ttl,
+		dialer:   dialer,
+		entries:  make(map[string]entry),
+	}
+}
+
+// LookupHost returns the cached addresses of host, resolving them again once they expire.
+func (c *Cache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	e, ok := c.entries[host]
+	c.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.addrs, nil
+	}
+
+	addrs, err := c.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[host] = entry{addrs: addrs, expires: now.Add(c.ttl)}
+	c.mu.Unlock()
+	return addrs, nil
+}
+
+// DialContext dials address like net.Dialer.DialContext, resolving its host through the cache.
+// It can be used as http.Transport.DialContext.
+func (c *Cache) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return c.dialer.DialContext(ctx, network, address)
+	}
+
+	addrs, err := c.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, addr := range addrs {
+		conn, err := c.dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,59 @@
+package dnscache
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// loopbackResolver resolves every host to the loopback address and counts the lookups.
+type loopbackResolver struct {
+	lookups atomic.Int64
+}
+
+func (r *loopbackResolver) LookupHost(_ context.Context, _ string) ([]string, error) {
+	r.lookups.Add(1)
+	return []string{"127.0.0.1"}, nil
+}
+
+func TestCacheResolvesOncePerTTL(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	t.Cleanup(func() { listener.Close() })
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		wait    time.Duration
+		lookups int64
+	}{
+		{name: "Within the TTL", ttl: time.Minute, lookups: 1},
+		{name: "After the TTL", ttl: time.Millisecond, wait: 10 * time.Millisecond, lookups: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &loopbackResolver{}
+			cache := New(resolver, tt.ttl)
+
+			for i := 0; i < 2; i++ {
+				if i > 0 {
+					time.Sleep(tt.wait)
+				}
+				conn, err := cache.DialContext(context.Background(), "tcp", net.JoinHostPort("upstream.test", port))
+				if !assert.NoError(t, err) {
+					return
+				}
+				conn.Close()
+			}
+
+			assert.Equal(t, tt.lookups, resolver.lookups.Load())
+		})
+	}
+}
@@ -0,0 +1,23 @@
+// Package docs holds the OpenAPI specification of the services.
+package docs
+
+import (
+	_ "embed"
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIYAML is the OpenAPI 3.0 specification of the services, maintained by hand in openapi.yaml.
+//
+//go:embed openapi.yaml
+var OpenAPIYAML []byte
+
+// OpenAPIJSON converts OpenAPIYAML to JSON.
+func OpenAPIJSON() ([]byte, error) {
+	var spec map[string]any
+	if err := yaml.Unmarshal(OpenAPIYAML, &spec); err != nil {
+		return nil, err
+	}
+	return json.Marshal(spec)
+}
@@ -0,0 +1,24 @@
+package dto
+
+// APICEPLocation is the answer of APICEP for a CEP.
+// Unknown CEPs are answered with Status 404 and OK false, sometimes with HTTP 200.
+type APICEPLocation struct {
+	Status   int    `json:"status"`
+	OK       bool   `json:"ok"`
+	Code     string `json:"code"`
+	State    string `json:"state"`
+	City     string `json:"city"`
+	District string `json:"district"`
+	Address  string `json:"address"`
+	Message  string `json:"message"`
+}
+
+// Location converts l to the location returned by ViaCEP.
+// APICEP does not return coordinates.
+func (l *APICEPLocation) Location() *Location {
+	return &Location{
+		CEP:      l.Code,
+		Location: l.City,
+		Erro:     !l.OK,
+	}
+}
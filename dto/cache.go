@@ -0,0 +1,7 @@
+package dto
+
+// CacheFlushResponse is the JSON body of the answers of /admin/cache/flush.
+type CacheFlushResponse struct {
+	Flushed        bool `json:"flushed"`
+	EntriesRemoved int  `json:"entries_removed"`
+}
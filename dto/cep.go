@@ -0,0 +1,57 @@
+package dto
+
+import "strings"
+
+// cepRegions names the postal regions of Brazil by the first digit of their CEPs.
+var cepRegions = [10]string{
+	"Grande São Paulo",
+	"Interior de São Paulo",
+	"Rio de Janeiro e Espírito Santo",
+	"Minas Gerais",
+	"Bahia e Sergipe",
+	"Pernambuco, Alagoas, Paraíba e Rio Grande do Norte",
+	"Ceará, Piauí, Maranhão, Pará, Amazonas, Acre, Amapá e Roraima",
+	"Distrito Federal, Goiás, Tocantins, Mato Grosso, Mato Grosso do Sul e Rondônia",
+	"Paraná e Santa Catarina",
+	"Rio Grande do Sul",
+}
+
+// CEPRegion returns the postal region encoded by the first digit of cep,
+// or an empty string when cep does not start with a digit.
+func CEPRegion(cep string) string {
+	if cep == "" || cep[0] < '0' || cep[0] > '9' {
+		return ""
+	}
+	return cepRegions[cep[0]-'0']
+}
+
+// CEP is a Brazilian postal code.
+type CEP string
+
+// IsValid reports whether c is made of exactly 8 digits, as expected by ViaCEP.
+// Unallocated ranges are not detected.
+func (c CEP) IsValid() bool {
+	if len(c) != 8 {
+		return false
+	}
+	for i := 0; i < len(c); i++ {
+		if c[i] < '0' || c[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Normalized returns c without the hyphen and surrounding spaces of the 12345-678 format.
+func (c CEP) Normalized() string {
+	return strings.ReplaceAll(strings.TrimSpace(string(c)), "-", "")
+}
+
+// Region returns the postal region of c, see CEPRegion.
+// Invalid CEPs have no region.
+func (c CEP) Region() string {
+	if !c.IsValid() {
+		return ""
+	}
+	return CEPRegion(string(c))
+}
@@ -0,0 +1,13 @@
+package dto
+
+// ConfigUpdateRequest is the JSON body of PUT /admin/config.
+// The settings left out are not changed.
+type ConfigUpdateRequest struct {
+	RateLimitRPS *float64 `json:"rate_limit_rps"`
+}
+
+// ConfigResponse is the runtime configuration answered by PUT /admin/config.
+type ConfigResponse struct {
+	// RateLimitRPS is 0 when the rate is not limited.
+	RateLimitRPS float64 `json:"rate_limit_rps"`
+}
@@ -0,0 +1,273 @@
+package dto
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemperatureConversion(t *testing.T) {
+	tests := []struct {
+		name       string
+		celsius    float64
+		fahrenheit float64
+		kelvin     float64
+	}{
+		{name: "Freezing point", celsius: 0, fahrenheit: 32, kelvin: 273.15},
+		{name: "Boiling point", celsius: 100, fahrenheit: 212, kelvin: 373.15},
+		{name: "Same in Celsius and Fahrenheit", celsius: -40, fahrenheit: -40, kelvin: 233.15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.fahrenheit, CelsiusToFahrenheit(tt.celsius), 1e-9)
+			assert.InDelta(t, tt.kelvin, CelsiusToKelvin(tt.celsius), 1e-9)
+
+			response, err := NewCEPWeatherResponse(&Location{Location: "Osasco"}, &Weather{Current: WeatherCurrent{TempC: tt.celsius, Humidity: 65}})
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.InDelta(t, tt.fahrenheit, response.TemperatureInFahrenheit, 1e-9)
+			assert.InDelta(t, tt.kelvin, response.TemperatureInKelvin, 1e-9)
+		})
+	}
+}
+
+// temperatureValues generates temperatures between absolute zero and 1000 °C for testing/quick.
+func temperatureValues(values []reflect.Value, r *rand.Rand) {
+	for i := range values {
+		values[i] = reflect.ValueOf(AbsoluteZeroCelsius + r.Float64()*(1000-AbsoluteZeroCelsius))
+	}
+}
+
+func TestTemperatureConversionRoundTrip(t *testing.T) {
+	config := &quick.Config{MaxCount: 10000, Values: temperatureValues}
+
+	roundTrip := func(f float64) bool {
+		return math.Abs(CelsiusToFahrenheit(FahrenheitToCelsius(f))-f) < 1e-9
+	}
+	assert.NoError(t, quick.Check(roundTrip, config))
+
+	nonNegativeKelvin := func(c float64) bool {
+		return CelsiusToKelvin(c) >= 0
+	}
+	assert.NoError(t, quick.Check(nonNegativeKelvin, config))
+
+	assert.Zero(t, CelsiusToKelvin(AbsoluteZeroCelsius))
+	assert.Zero(t, CelsiusToKelvin(-300), "below absolute zero is clamped to 0 K")
+}
+
+func TestNewCEPWeatherResponseFeelsLike(t *testing.T) {
+	response, err := NewCEPWeatherResponse(&Location{Location: "Osasco"}, &Weather{Current: WeatherCurrent{TempC: 25, FeelsLikeF: 80.2}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 80.2, response.FeelsLikeInFahrenheit)
+}
+
+func TestWeatherAPIResponseRoundTrip(t *testing.T) {
+	fixture := `{
+		"location": {"name": "Osasco", "country": "Brazil"},
+		"current": {
+			"last_updated": "2024-06-17 10:00",
+			"temp_c": 25.0,
+			"temp_f": 77.0,
+			"condition": {"text": "Partly cloudy", "code": 1003},
+			"wind_mph": 5.6,
+			"wind_kph": 9.0,
+			"humidity": 65,
+			"feelslike_c": 26.1,
+			"feelslike_f": 79.0
+		}
+	}`
+
+	var weather Weather
+	if !assert.NoError(t, json.Unmarshal([]byte(fixture), &weather)) {
+		return
+	}
+	response, err := NewCEPWeatherResponse(&Location{Location: "Osasco"}, &weather)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &CEPWeatherResponse{
+		Location:                "Osasco",
+		TemperatureInCelcius:    25.0,
+		TemperatureInFahrenheit: 77.0,
+		TemperatureInKelvin:     298.15,
+		FeelsLikeInCelsius:      26.1,
+		FeelsLikeInFahrenheit:   79.0,
+		Humidity:                65,
+		WindKph:                 9.0,
+		WindMph:                 5.6,
+		Condition:               "Partly cloudy",
+	}, response)
+}
+
+func TestLocationCoordinates(t *testing.T) {
+	tests := []struct {
+		name      string
+		latitude  string
+		longitude string
+		lat       float64
+		lng       float64
+	}{
+		{name: "Coordinates present", latitude: "-23.5328", longitude: "-46.7917", lat: -23.5328, lng: -46.7917},
+		{name: "Coordinates missing", latitude: "", longitude: "", lat: 0, lng: 0},
+		{name: "Coordinates malformed", latitude: "north", longitude: "-46.7917", lat: 0, lng: -46.7917},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := &Location{Location: "Osasco", Latitude: tt.latitude, Longitude: tt.longitude}
+
+			response, err := NewCEPWeatherResponse(location, &Weather{Current: WeatherCurrent{TempC: 25}})
+
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.lat, response.Lat)
+			assert.Equal(t, tt.lng, response.Lng)
+		})
+	}
+}
+
+func TestNewCEPWeatherResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		location *Location
+		weather  *Weather
+		want     *CEPWeatherResponse
+		err      error
+	}{
+		{
+			name:     "normal Brazilian city",
+			location: &Location{CEP: "06233-903", Location: "Osasco", Latitude: "-23.5328", Longitude: "-46.7917"},
+			weather: &Weather{Current: WeatherCurrent{
+				TempC: 25, FeelsLikeC: 26, FeelsLikeF: 78.8, Humidity: 65, WindKph: 9, WindMph: 5.6,
+				Condition: WeatherCondition{Text: "Sunny"},
+			}},
+			want: &CEPWeatherResponse{
+				Location: "Osasco", Lat: -23.5328, Lng: -46.7917,
+				TemperatureInCelcius: 25, TemperatureInFahrenheit: 77, TemperatureInKelvin: 298.15,
+				FeelsLikeInCelsius: 26, FeelsLikeInFahrenheit: 78.8, Humidity: 65, WindKph: 9, WindMph: 5.6,
+				Condition: "Sunny",
+			},
+		},
+		{
+			name:     "city with accent characters",
+			location: &Location{CEP: "01001-000", Location: "São Paulo", Latitude: "-23.5505", Longitude: "-46.6333"},
+			weather: &Weather{Current: WeatherCurrent{
+				TempC: 18, FeelsLikeC: 18, FeelsLikeF: 64.4, Humidity: 80, WindKph: 11, WindMph: 6.8,
+				Condition: WeatherCondition{Text: "Chuva fraca"},
+			}},
+			want: &CEPWeatherResponse{
+				Location: "São Paulo", Lat: -23.5505, Lng: -46.6333,
+				TemperatureInCelcius: 18, TemperatureInFahrenheit: 64.4, TemperatureInKelvin: 291.15,
+				FeelsLikeInCelsius: 18, FeelsLikeInFahrenheit: 64.4, Humidity: 80, WindKph: 11, WindMph: 6.8,
+				Condition: "Chuva fraca",
+			},
+		},
+		{
+			name:     "below-freezing temperature",
+			location: &Location{CEP: "88625-000", Location: "Urupema"},
+			weather: &Weather{Current: WeatherCurrent{
+				TempC: -5, FeelsLikeC: -9, FeelsLikeF: 15.8, Humidity: 90, WindKph: 14, WindMph: 8.7,
+				Condition: WeatherCondition{Text: "Freezing fog"},
+			}},
+			want: &CEPWeatherResponse{
+				Location:             "Urupema",
+				TemperatureInCelcius: -5, TemperatureInFahrenheit: 23, TemperatureInKelvin: 268.15,
+				FeelsLikeInCelsius: -9, FeelsLikeInFahrenheit: 15.8, Humidity: 90, WindKph: 14, WindMph: 8.7,
+				Condition: "Freezing fog",
+			},
+		},
+		{
+			name:     "freezing point",
+			location: &Location{Location: "Urupema"},
+			weather:  &Weather{Current: WeatherCurrent{Condition: WeatherCondition{Text: "Mist"}}},
+			want:     &CEPWeatherResponse{Location: "Urupema", TemperatureInFahrenheit: 32, TemperatureInKelvin: 273.15, Condition: "Mist"},
+		},
+		{name: "empty weather", location: &Location{Location: "Osasco"}, weather: &Weather{}, err: ErrIncompleteWeatherResponse},
+		{name: "missing weather", location: &Location{Location: "Osasco"}, err: ErrIncompleteWeatherResponse},
+		{name: "empty location", location: &Location{}, weather: &Weather{Current: WeatherCurrent{TempC: 25}}, err: ErrIncompleteWeatherResponse},
+		{name: "empty DTOs", location: &Location{}, weather: &Weather{}, err: ErrIncompleteWeatherResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := NewCEPWeatherResponse(tt.location, tt.weather)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, response)
+		})
+	}
+}
+
+func TestNewCEPForecastResponse(t *testing.T) {
+	forecast := &Forecast{Forecast: ForecastDays{ForecastDay: []ForecastDay{{
+		Date: "2024-06-17",
+		Day:  ForecastDayWeather{MaxTempC: 25, MinTempC: 15, DailyChanceOfRain: 40, Condition: WeatherCondition{Text: "Sunny"}},
+	}}}}
+	tests := []struct {
+		name     string
+		location *Location
+		forecast *Forecast
+		want     *CEPForecastResponse
+		err      error
+	}{
+		{
+			name:     "forecast of a city",
+			location: &Location{Location: "Osasco"},
+			forecast: forecast,
+			want: &CEPForecastResponse{Location: "Osasco", Days: []DailyForecastResponse{{
+				Date:                    "2024-06-17",
+				MaxTemperatureInCelsius: 25, MaxTemperatureInFahrenheit: 77,
+				MinTemperatureInCelsius: 15, MinTemperatureInFahrenheit: 59,
+				ChanceOfRain: 40, Condition: "Sunny",
+			}}},
+		},
+		{name: "missing location", forecast: forecast, err: ErrIncompleteWeatherResponse},
+		{name: "empty location", location: &Location{}, forecast: forecast, err: ErrIncompleteWeatherResponse},
+		{name: "missing forecast", location: &Location{Location: "Osasco"}, err: ErrIncompleteWeatherResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := NewCEPForecastResponse(tt.location, tt.forecast)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, response)
+		})
+	}
+}
+
+func TestCEP(t *testing.T) {
+	tests := []struct {
+		name       string
+		cep        CEP
+		valid      bool
+		normalized string
+		region     string
+	}{
+		{name: "Grande São Paulo", cep: "06233903", valid: true, normalized: "06233903", region: "Grande São Paulo"},
+		{name: "Rio Grande do Sul", cep: "90010000", valid: true, normalized: "90010000", region: "Rio Grande do Sul"},
+		{name: "Hyphenated", cep: "01310-100", valid: false, normalized: "01310100", region: ""},
+		{name: "Surrounding spaces", cep: " 01310-100 ", valid: false, normalized: "01310100", region: ""},
+		{name: "Letters", cep: "0623abcd", valid: false, normalized: "0623abcd", region: ""},
+		{name: "Too short", cep: "0623390", valid: false, normalized: "0623390", region: ""},
+		{name: "Empty", cep: "", valid: false, normalized: "", region: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.valid, tt.cep.IsValid())
+			assert.Equal(t, tt.normalized, tt.cep.Normalized())
+			assert.Equal(t, tt.region, tt.cep.Region())
+		})
+	}
+}
+
+func TestCEPRegion(t *testing.T) {
+	assert.Equal(t, "Interior de São Paulo", CEPRegion("13083970"))
+	assert.Equal(t, "Paraná e Santa Catarina", CEPRegion("8"))
+	assert.Empty(t, CEPRegion("-1"))
+	assert.Empty(t, CEPRegion(""))
+}
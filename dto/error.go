@@ -0,0 +1,6 @@
+package dto
+
+// ErrorResponse is the JSON body of error answers.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
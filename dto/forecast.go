@@ -0,0 +1,23 @@
+package dto
+
+// Forecast is the daily forecast returned by the WeatherAPI forecast endpoint.
+type Forecast struct {
+	Forecast ForecastDays `json:"forecast"`
+}
+
+type ForecastDays struct {
+	ForecastDay []ForecastDay `json:"forecastday"`
+}
+
+type ForecastDay struct {
+	// Date in the YYYY-MM-DD format.
+	Date string             `json:"date"`
+	Day  ForecastDayWeather `json:"day"`
+}
+
+type ForecastDayWeather struct {
+	MaxTempC          float64          `json:"maxtemp_c"`
+	MinTempC          float64          `json:"mintemp_c"`
+	DailyChanceOfRain int              `json:"daily_chance_of_rain"`
+	Condition         WeatherCondition `json:"condition"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "fmt"
+
+type CEPForecastResponse struct {
+	Location string                  `json:"city"`
+	Days     []DailyForecastResponse `json:"days"`
+}
+
+type DailyForecastResponse struct {
+	Date                       string  `json:"date"`
+	MaxTemperatureInCelsius    float64 `json:"max_temp_C"`
+	MaxTemperatureInFahrenheit float64 `json:"max_temp_F"`
+	MinTemperatureInCelsius    float64 `json:"min_temp_C"`
+	MinTemperatureInFahrenheit float64 `json:"min_temp_F"`
+	ChanceOfRain               int     `json:"chance_of_rain"`
+	Condition                  string  `json:"condition"`
+}
+
+// NewCEPForecastResponse combines the location of a CEP and the daily forecast of its city.
+// It returns ErrIncompleteWeatherResponse when the city name or the forecast is missing.
+func NewCEPForecastResponse(location *Location, forecast *Forecast) (*CEPForecastResponse, error) {
+	if location == nil || location.Location == "" {
+		return nil, fmt.Errorf("%w: missing city name", ErrIncompleteWeatherResponse)
+	}
+	if forecast == nil {
+		return nil, fmt.Errorf("%w: missing forecast of %s", ErrIncompleteWeatherResponse, location.Location)
+	}
+	days := make([]DailyForecastResponse, 0, len(forecast.Forecast.ForecastDay))
+	for _, forecastDay := range forecast.Forecast.ForecastDay {
+		days = append(days, DailyForecastResponse{
+			Date:                       forecastDay.Date,
+			MaxTemperatureInCelsius:    forecastDay.Day.MaxTempC,
+			MaxTemperatureInFahrenheit: CelsiusToFahrenheit(forecastDay.Day.MaxTempC),
+			MinTemperatureInCelsius:    forecastDay.Day.MinTempC,
+			MinTemperatureInFahrenheit: CelsiusToFahrenheit(forecastDay.Day.MinTempC),
+			ChanceOfRain:               forecastDay.Day.DailyChanceOfRain,
+			Condition:                  forecastDay.Day.Condition.Text,
+		})
+	}
+	return &CEPForecastResponse{Location: location.Location, Days: days}, nil
+}
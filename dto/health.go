@@ -0,0 +1,7 @@
+package dto
+
+type HealthResponse struct {
+	Status string `json:"status"`
+	// Checks holds the result of every dependency check when the service is degraded.
+	Checks map[string]string `json:"checks,omitempty"`
+}
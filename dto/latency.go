@@ -0,0 +1,7 @@
+package dto
+
+type LatencyResponse struct {
+	P99Ms float64 `json:"p99_ms"`
+	P95Ms float64 `json:"p95_ms"`
+	P50Ms float64 `json:"p50_ms"`
+}
@@ -1,17 +1,77 @@
 package dto
 
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+)
+
+// ErrIncompleteWeatherResponse is returned by NewCEPWeatherResponse and NewCEPForecastResponse
+// when the city, the current weather or the forecast is missing.
+var ErrIncompleteWeatherResponse = fmt.Errorf("incomplete weather response")
+
+// CEPWeatherResponse is the weather of the city of a CEP, encoded in JSON or in XML as a weather element.
 type CEPWeatherResponse struct {
-	Location                string  `json:"city"`
-	TemperatureInCelcius    float64 `json:"temp_C"`
-	TemperatureInFahrenheit float64 `json:"temp_F"`
-	TemperatureInKelvin     float64 `json:"temp_K"`
+	XMLName                 xml.Name `json:"-" xml:"weather"`
+	Location                string   `json:"city" xml:"city"`
+	Lat                     float64  `json:"lat" xml:"lat"`
+	Lng                     float64  `json:"lng" xml:"lng"`
+	TemperatureInCelcius    float64  `json:"temp_C" xml:"temp_C"`
+	TemperatureInFahrenheit float64  `json:"temp_F" xml:"temp_F"`
+	TemperatureInKelvin     float64  `json:"temp_K" xml:"temp_K"`
+	FeelsLikeInCelsius      float64  `json:"feelslike_C" xml:"feelslike_C"`
+	FeelsLikeInFahrenheit   float64  `json:"feelslike_F" xml:"feelslike_F"`
+	Humidity                int      `json:"humidity" xml:"humidity"`
+	WindKph                 float64  `json:"wind_kph" xml:"wind_kph"`
+	WindMph                 float64  `json:"wind_mph" xml:"wind_mph"`
+	Condition               string   `json:"condition" xml:"condition"`
+}
+
+// CSVRecords encodes r in CSV, as a header of its field names followed by their values.
+func (r *CEPWeatherResponse) CSVRecords() [][]string {
+	formatFloat := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	return [][]string{
+		{"city", "lat", "lng", "temp_C", "temp_F", "temp_K", "feelslike_C", "feelslike_F", "humidity", "wind_kph", "wind_mph", "condition"},
+		{
+			r.Location,
+			formatFloat(r.Lat),
+			formatFloat(r.Lng),
+			formatFloat(r.TemperatureInCelcius),
+			formatFloat(r.TemperatureInFahrenheit),
+			formatFloat(r.TemperatureInKelvin),
+			formatFloat(r.FeelsLikeInCelsius),
+			formatFloat(r.FeelsLikeInFahrenheit),
+			strconv.Itoa(r.Humidity),
+			formatFloat(r.WindKph),
+			formatFloat(r.WindMph),
+			r.Condition,
+		},
+	}
 }
 
-func NewCEPWeatherResponse(location *Location, weather *Weather) *CEPWeatherResponse {
+// NewCEPWeatherResponse combines the location of a CEP and the weather of its city.
+// It returns ErrIncompleteWeatherResponse when the city name or the current weather is missing.
+// The current weather is missing when all of its fields are zero, since 0 °C is a valid temperature.
+func NewCEPWeatherResponse(location *Location, weather *Weather) (*CEPWeatherResponse, error) {
+	if location == nil || location.Location == "" {
+		return nil, fmt.Errorf("%w: missing city name", ErrIncompleteWeatherResponse)
+	}
+	if weather == nil || weather.Current == (WeatherCurrent{}) {
+		return nil, fmt.Errorf("%w: missing current weather of %s", ErrIncompleteWeatherResponse, location.Location)
+	}
+	lat, lng := location.Coordinates()
 	return &CEPWeatherResponse{
 		Location:                location.Location,
+		Lat:                     lat,
+		Lng:                     lng,
 		TemperatureInCelcius:    weather.Current.TempC,
-		TemperatureInFahrenheit: weather.Current.TempF,
-		TemperatureInKelvin:     weather.Current.TempC + 273.15,
-	}
+		TemperatureInFahrenheit: CelsiusToFahrenheit(weather.Current.TempC),
+		TemperatureInKelvin:     CelsiusToKelvin(weather.Current.TempC),
+		FeelsLikeInCelsius:      weather.Current.FeelsLikeC,
+		FeelsLikeInFahrenheit:   weather.Current.FeelsLikeF,
+		Humidity:                weather.Current.Humidity,
+		WindKph:                 weather.Current.WindKph,
+		WindMph:                 weather.Current.WindMph,
+		Condition:               weather.Current.Condition.Text,
+	}, nil
 }
@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/leoseiji/go-tracing/docs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDTOSchemaMatchesOpenAPISpec(t *testing.T) {
+	spec, err := openapi3.NewLoader().LoadFromData(docs.OpenAPIYAML)
+	if !assert.NoError(t, err) || !assert.NoError(t, spec.Validate(context.Background())) {
+		return
+	}
+
+	weatherResponse, err := NewCEPWeatherResponse(
+		&Location{Location: "Osasco", Latitude: "-23.5328", Longitude: "-46.7917"},
+		&Weather{Current: WeatherCurrent{TempC: 28.5, FeelsLikeC: 30, FeelsLikeF: 86, Humidity: 40, WindKph: 10, WindMph: 6.2, Condition: WeatherCondition{Text: "Sunny"}}},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name   string
+		schema string
+		value  any
+	}{
+		{name: "CEPWeatherResponse", schema: "CEPWeatherResponse", value: weatherResponse},
+		{name: "WeatherCepRequest", schema: "WeatherCepRequest", value: WeatherCepRequest{Cep: "06233903"}},
+		{name: "WeatherBatchRequest", schema: "WeatherBatchRequest", value: WeatherBatchRequest{CEPs: []string{"06233903"}}},
+		{name: "WeatherBatchError", schema: "WeatherBatchError", value: WeatherBatchError{CEP: "12345678", Status: 404, Error: "can not find zipcode"}},
+		{name: "DailyForecastResponse", schema: "DailyForecastResponse", value: DailyForecastResponse{Date: "2024-06-17", Condition: "Sunny"}},
+		{name: "HealthResponse", schema: "HealthResponse", value: HealthResponse{Status: "degraded", Checks: map[string]string{"viacep": "unreachable"}}},
+		{name: "VersionResponse", schema: "VersionResponse", value: VersionResponse{Version: "v1.2.3", Commit: "abc123", BuiltAt: time.Date(2024, 6, 17, 12, 0, 0, 0, time.UTC)}},
+		{name: "ErrorResponse", schema: "ErrorResponse", value: ErrorResponse{Error: "invalid zipcode"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schemaRef, ok := spec.Components.Schemas[tt.schema]
+			if !assert.True(t, ok, "schema %s is not in the spec", tt.schema) {
+				return
+			}
+			body, err := json.Marshal(tt.value)
+			if !assert.NoError(t, err) {
+				return
+			}
+			var document map[string]any
+			if !assert.NoError(t, json.Unmarshal(body, &document)) {
+				return
+			}
+
+			assert.NoError(t, schemaRef.Value.VisitJSON(document))
+			// The schemas allow additional properties, so compare the fields explicitly:
+			// a field missing from either side means the DTO and the spec drifted apart.
+			var fields, properties []string
+			for field := range document {
+				fields = append(fields, field)
+			}
+			for property := range schemaRef.Value.Properties {
+				properties = append(properties, property)
+			}
+			slices.Sort(fields)
+			slices.Sort(properties)
+			assert.Equal(t, properties, fields)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package dto
+
+// AbsoluteZeroCelsius is the lowest temperature, 0 K, in Celsius.
+const AbsoluteZeroCelsius = -273.15
+
+// CelsiusToFahrenheit converts a temperature from Celsius to Fahrenheit.
+func CelsiusToFahrenheit(celsius float64) float64 {
+	return celsius*9/5 + 32
+}
+
+// FahrenheitToCelsius converts a temperature from Fahrenheit to Celsius.
+func FahrenheitToCelsius(fahrenheit float64) float64 {
+	return (fahrenheit - 32) * 5 / 9
+}
+
+// CelsiusToKelvin converts a temperature from Celsius to Kelvin.
+// Temperatures below absolute zero, which are physically impossible, are clamped to 0 K.
+func CelsiusToKelvin(celsius float64) float64 {
+	return max(celsius-AbsoluteZeroCelsius, 0)
+}
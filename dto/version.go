@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+type VersionResponse struct {
+	Version string    `json:"version"`
+	Commit  string    `json:"commit"`
+	BuiltAt time.Time `json:"built_at"`
+}
@@ -1,6 +1,47 @@
 package dto
 
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
 type Location struct {
-	CEP      string `json:"cep"`
-	Location string `json:"localidade"`
+	CEP      string `json:"cep" xml:"cep"`
+	Location string `json:"localidade" xml:"localidade"`
+	// Latitude and Longitude are only returned by the IBGE-enhanced endpoint,
+	// as decimal strings.
+	Latitude  string `json:"latitude" xml:"latitude"`
+	Longitude string `json:"longitude" xml:"longitude"`
+	// Erro is set by ViaCEP, with HTTP 200, for CEPs it does not know.
+	Erro bool `json:"erro" xml:"erro"`
+}
+
+// UnmarshalJSON decodes erro both as the string "true" ViaCEP returns and as a boolean.
+func (l *Location) UnmarshalJSON(data []byte) error {
+	type location Location
+	aux := struct {
+		*location
+		Erro json.RawMessage `json:"erro"`
+	}{location: (*location)(l)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	switch string(aux.Erro) {
+	case "", "null", "false", `"false"`:
+		l.Erro = false
+	case "true", `"true"`:
+		l.Erro = true
+	default:
+		return fmt.Errorf("invalid erro %s: expected true or false", aux.Erro)
+	}
+	return nil
+}
+
+// Coordinates parses the latitude and longitude of l.
+// Missing or malformed coordinates are returned as zero.
+func (l *Location) Coordinates() (lat, lng float64) {
+	lat, _ = strconv.ParseFloat(l.Latitude, 64)
+	lng, _ = strconv.ParseFloat(l.Longitude, 64)
+	return lat, lng
 }
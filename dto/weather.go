@@ -5,7 +5,17 @@ type Weather struct {
 }
 
 type WeatherCurrent struct {
-	LastUpdated string  `json:"last_updated"`
-	TempC       float64 `json:"temp_c"`
-	TempF       float64 `json:"temp_f"`
+	LastUpdated string           `json:"last_updated"`
+	TempC       float64          `json:"temp_c"`
+	TempF       float64          `json:"temp_f"`
+	FeelsLikeC  float64          `json:"feelslike_c"`
+	FeelsLikeF  float64          `json:"feelslike_f"`
+	Humidity    int              `json:"humidity"`
+	WindKph     float64          `json:"wind_kph"`
+	WindMph     float64          `json:"wind_mph"`
+	Condition   WeatherCondition `json:"condition"`
+}
+
+type WeatherCondition struct {
+	Text string `json:"text"`
 }
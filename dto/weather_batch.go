@@ -0,0 +1,24 @@
+package dto
+
+type WeatherBatchRequest struct {
+	CEPs []string `json:"ceps"`
+}
+
+// WeatherBatchResponse holds the outcome of every CEP of a batch lookup,
+// in the order they were requested.
+type WeatherBatchResponse struct {
+	Results []WeatherBatchResult `json:"results"`
+	Errors  []WeatherBatchError  `json:"errors"`
+}
+
+type WeatherBatchResult struct {
+	CEP     string              `json:"cep"`
+	Weather *CEPWeatherResponse `json:"weather"`
+}
+
+type WeatherBatchError struct {
+	CEP string `json:"cep"`
+	// Status is the HTTP status code a single lookup of the CEP would have been answered with.
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+}
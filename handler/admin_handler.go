@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/leoseiji/go-tracing/dto"
+	"github.com/leoseiji/go-tracing/middleware"
+)
+
+var ErrRateLimitRPSInvalid = fmt.Errorf("rate_limit_rps must not be negative")
+
+// NewCacheFlushHandler empties cache, so that the next lookups reach the upstreams again.
+// It answers how many entries were removed.
+func NewCacheFlushHandler(cache *CachingCEPClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		removed := cache.Flush()
+		log.Printf("CEP cache flushed, %d entries removed", removed)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(dto.CacheFlushResponse{Flushed: true, EntriesRemoved: removed})
+	}
+}
+
+// NewConfigHandler updates the runtime configuration with the JSON body of the request, see dto.ConfigUpdateRequest.
+// The changes apply to the next requests, and the resulting configuration is answered.
+// A rate_limit_rps of 0 stops limiting the rate.
+func NewConfigHandler(rateLimiter *middleware.RateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var update dto.ConfigUpdateRequest
+		if !decodeJSONBody(w, r, &update) {
+			return
+		}
+		if update.RateLimitRPS != nil {
+			if *update.RateLimitRPS < 0 {
+				http.Error(w, ErrRateLimitRPSInvalid.Error(), http.StatusBadRequest)
+				return
+			}
+			rateLimiter.SetRPS(*update.RateLimitRPS)
+			log.Printf("rate limit set to %g requests per second", *update.RateLimitRPS)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(dto.ConfigResponse{RateLimitRPS: rateLimiter.RPS()})
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/leoseiji/go-tracing/clock"
+	"github.com/leoseiji/go-tracing/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheFlushEndpointClearsEntries(t *testing.T) {
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	var viaCEPCalls atomic.Int64
+	countingViaCEP := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		viaCEPCalls.Add(1)
+		viaCEP.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(countingViaCEP.Close)
+	cepCache := NewCachingCEPClient(NewViaCEPClient(ViaCEPConfig{BaseURL: countingViaCEP.URL}), 0, 0, nil)
+	router := NewRouter(RouterConfig{
+		ServiceA: NewServiceAHandler(ServiceAConfig{}),
+		ServiceB: NewServiceBHandler(ServiceBConfig{
+			CEPClient:       cepCache,
+			WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+		}),
+		AdminToken: "admin",
+		CEPCache:   cepCache,
+	})
+	getWeather := func() {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/weather-service-b/06233903", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+
+	_, err := cepCache.GetLocation(context.Background(), "06233903")
+	if !assert.NoError(t, err) {
+		return
+	}
+	getWeather()
+	assert.Equal(t, int64(1), viaCEPCalls.Load())
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/cache/flush", nil)
+	req.Header.Set(middleware.AdminTokenHeader, "admin")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"flushed":true,"entries_removed":1}`, rr.Body.String())
+
+	getWeather()
+	assert.Equal(t, int64(2), viaCEPCalls.Load())
+}
+
+func TestAdminEndpointReturns401WithoutToken(t *testing.T) {
+	router := NewRouter(RouterConfig{
+		ServiceA:   NewServiceAHandler(ServiceAConfig{}),
+		ServiceB:   NewServiceBHandler(ServiceBConfig{}),
+		AdminToken: "admin",
+		CEPCache:   NewCachingCEPClient(nil, 0, 0, nil),
+	})
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{name: "Missing token", status: http.StatusUnauthorized},
+		{name: "Wrong token", token: "guess", status: http.StatusUnauthorized},
+		{name: "Correct token", token: "admin", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/cache/flush", nil)
+			if tt.token != "" {
+				req.Header.Set(middleware.AdminTokenHeader, tt.token)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			if tt.status == http.StatusUnauthorized {
+				assert.JSONEq(t, `{"error": "unauthorized"}`, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestPutConfigEndpointUpdatesRateLimit(t *testing.T) {
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	router := NewRouter(RouterConfig{
+		ServiceA: NewServiceAHandler(ServiceAConfig{}),
+		ServiceB: NewServiceBHandler(ServiceBConfig{
+			CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+			WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+		}),
+		AdminToken: "admin",
+		// The fake time does not move, so both requests are made within the same second.
+		Clock: clock.NewFakeClock(time.Now()),
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/config", strings.NewReader(`{"rate_limit_rps": 0.1}`))
+	req.Header.Set(middleware.AdminTokenHeader, "admin")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"rate_limit_rps": 0.1}`, rr.Body.String())
+
+	statuses := make([]int, 0, 2)
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/weather-service-b/06233903", nil))
+		statuses = append(statuses, rr.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, statuses)
+}
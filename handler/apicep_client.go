@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/leoseiji/go-tracing/dnscache"
+	"github.com/leoseiji/go-tracing/dto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// DefaultAPICEPBaseURL is the APICEP API used when APICEPConfig.BaseURL is empty.
+const DefaultAPICEPBaseURL = "https://cdn.apicep.com"
+
+type APICEPConfig struct {
+	// BaseURL of the APICEP API, without a trailing slash. Defaults to DefaultAPICEPBaseURL.
+	BaseURL string
+	// Timeout bounds each lookup, including reading the response. Defaults to DefaultViaCEPTimeout.
+	Timeout time.Duration
+	// HTTPClient used for the requests. Defaults to a client resolving hosts through Resolver
+	// and sending the User-Agent of the service.
+	HTTPClient *http.Client
+	// Resolver used for DNS lookups by the default HTTPClient, whose answers are cached.
+	// Defaults to net.DefaultResolver.
+	Resolver dnscache.Resolver
+	// DNSCacheTTL is how long the default HTTPClient caches the addresses of the hosts. Defaults to dnscache.DefaultTTL.
+	DNSCacheTTL time.Duration
+	// Timeouts of the connections and responses of the default HTTPClient, within Timeout.
+	Timeouts Timeouts
+	// TLSConfig of the default HTTPClient, see NewUpstreamTLSConfig. Defaults to the system settings.
+	TLSConfig *tls.Config
+}
+
+// APICEPClient is a CEPClient backed by the APICEP HTTP API, an alternative to ViaCEP.
+type APICEPClient struct {
+	baseURL    string
+	timeout    time.Duration
+	httpClient *http.Client
+}
+
+func NewAPICEPClient(cfg APICEPConfig) *APICEPClient {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultAPICEPBaseURL
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Transport: newUserAgentTransport(newTransport(cfg.Resolver, cfg.DNSCacheTTL, cfg.Timeouts, cfg.TLSConfig))}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultViaCEPTimeout
+	}
+	return &APICEPClient{
+		baseURL:    cfg.BaseURL,
+		timeout:    cfg.Timeout,
+		httpClient: cfg.HTTPClient,
+	}
+}
+
+// GetLocation looks up cep on APICEP. It returns ErrCEPInvalid when cep is not 8 digits.
+func (c *APICEPClient) GetLocation(ctx context.Context, cep string) (*dto.Location, error) {
+	if !dto.CEP(cep).IsValid() {
+		return nil, ErrCEPInvalid
+	}
+	tracer := otel.Tracer("weather-service-b-get-location-by-cep")
+	_, span := tracer.Start(ctx, "getLocationByCEPOnAPICEP")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	// APICEP names its files after the 12345-678 format.
+	url := fmt.Sprintf("%s/file/apicep/%s-%s.json", c.baseURL, cep[:5], cep[5:])
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		log.Printf("error creating APICEP request. Err:%s", err.Error())
+		return nil, err
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		log.Printf("error executing APICEP request%s. Err:%s", timeoutSuffix(ctx, err), err.Error())
+		return nil, canceledError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var apiCEPLocation dto.APICEPLocation
+		if err := json.NewDecoder(resp.Body).Decode(&apiCEPLocation); err != nil {
+			log.Printf("error while converting APICEP result. Err:%s", err.Error())
+			return nil, err
+		}
+		location := apiCEPLocation.Location()
+		if location.Erro || location.CEP == "" {
+			return nil, ErrCEPNotFound
+		}
+		return location, nil
+
+	case http.StatusNotFound:
+		return nil, ErrCEPNotFound
+
+	default:
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+}
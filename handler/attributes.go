@@ -0,0 +1,30 @@
+package handler
+
+import "go.opentelemetry.io/otel/attribute"
+
+// Span attribute keys recorded by the handlers.
+// Never record API keys or URLs containing them.
+const (
+	cepKey = attribute.Key("cep")
+	// cepRegionKey holds the postal region encoded by the first digit of the CEP.
+	cepRegionKey    = attribute.Key("cep.region")
+	locationNameKey = attribute.Key("location.name")
+	weatherTempCKey = attribute.Key("weather.temp_c")
+	forecastDaysKey = attribute.Key("forecast.days")
+	// cepFallbackKey is set on the cep.fallback event, when the secondary CEPClient is used.
+	cepFallbackKey = attribute.Key("cep.fallback")
+	// correlationIDKey holds the client correlation ID received as baggage.
+	correlationIDKey = attribute.Key("correlation.id")
+	// linkTypeKey is set on the span links, telling how the linked span relates to the span.
+	linkTypeKey = attribute.Key("link.type")
+	// serviceBStatusCodeKey holds the status of an unexpected answer of service B.
+	serviceBStatusCodeKey = attribute.Key("service_b.status_code")
+)
+
+// Metric attribute keys recorded by the handlers.
+const (
+	handlerKey = attribute.Key("handler")
+	statusKey  = attribute.Key("status")
+	// serviceKey tells service A and service B apart on the instruments both record.
+	serviceKey = attribute.Key("service")
+)
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/leoseiji/go-tracing/clock"
+	"github.com/leoseiji/go-tracing/dto"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// DefaultCEPCacheTTL is how long the locations of the CEPs are cached, since they rarely change.
+	DefaultCEPCacheTTL = 24 * time.Hour
+	// DefaultCEPCacheMaxEntries is the number of CEPs cached by default.
+	DefaultCEPCacheMaxEntries = 10000
+)
+
+// CachingCEPClient is a CEPClient caching the locations found by another CEPClient for a TTL.
+// The errors, including the CEPs not found, are not cached.
+// Concurrent lookups of a CEP missing from the cache share a single lookup on the other CEPClient.
+// Once the cache is full, the least recently used CEP is evicted to make room for a new one,
+// so the lookups of many distinct CEPs do not grow it without bound.
+type CachingCEPClient struct {
+	next       CEPClient
+	ttl        time.Duration
+	maxEntries int
+	clock      clock.Clock
+
+	mu sync.Mutex
+	// entries holds the elements of recentlyUsed by CEP.
+	entries map[string]*list.Element
+	// recentlyUsed orders the cepCacheEntry from the most to the least recently used.
+	recentlyUsed *list.List
+	lookups      singleflight.Group
+}
+
+type cepCacheEntry struct {
+	cep       string
+	location  *dto.Location
+	expiresAt time.Time
+}
+
+// NewCachingCEPClient caches the locations found by next for ttl, which defaults to DefaultCEPCacheTTL,
+// keeping up to maxEntries CEPs, which defaults to DefaultCEPCacheMaxEntries.
+// The entries expire on the time of clk, which defaults to clock.Real.
+func NewCachingCEPClient(next CEPClient, ttl time.Duration, maxEntries int, clk clock.Clock) *CachingCEPClient {
+	if ttl <= 0 {
+		ttl = DefaultCEPCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultCEPCacheMaxEntries
+	}
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	return &CachingCEPClient{
+		next:         next,
+		ttl:          ttl,
+		maxEntries:   maxEntries,
+		clock:        clk,
+		entries:      make(map[string]*list.Element),
+		recentlyUsed: list.New(),
+	}
+}
+
+func (c *CachingCEPClient) GetLocation(ctx context.Context, cep string) (*dto.Location, error) {
+	now := c.clock.Now()
+	if location, ok := c.get(cep, now); ok {
+		return location, nil
+	}
+
+	// The shared lookup outlives the callers that leave, the ones still waiting get its result.
+	lookup := c.lookups.DoChan(cep, func() (any, error) {
+		location, err := c.next.GetLocation(context.WithoutCancel(ctx), cep)
+		if err != nil {
+			return nil, err
+		}
+		c.put(cep, location, now.Add(c.ttl))
+		return location, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, canceledError(ctx, ctx.Err())
+	case result := <-lookup:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*dto.Location), nil
+	}
+}
+
+// get returns the location of cep cached and not expired at now, marking it as the most recently used.
+// An expired entry is removed.
+func (c *CachingCEPClient) get(cep string, now time.Time) (*dto.Location, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[cep]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(cepCacheEntry)
+	if !now.Before(entry.expiresAt) {
+		c.remove(element)
+		return nil, false
+	}
+	c.recentlyUsed.MoveToFront(element)
+	return entry.location, true
+}
+
+// put caches location for cep until expiresAt, evicting the least recently used entry when the cache is full.
+func (c *CachingCEPClient) put(cep string, location *dto.Location, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := cepCacheEntry{cep: cep, location: location, expiresAt: expiresAt}
+	if element, ok := c.entries[cep]; ok {
+		element.Value = entry
+		c.recentlyUsed.MoveToFront(element)
+		return
+	}
+	c.entries[cep] = c.recentlyUsed.PushFront(entry)
+	if c.recentlyUsed.Len() > c.maxEntries {
+		c.remove(c.recentlyUsed.Back())
+	}
+}
+
+// remove removes element from the cache. c.mu must be held.
+func (c *CachingCEPClient) remove(element *list.Element) {
+	c.recentlyUsed.Remove(element)
+	delete(c.entries, element.Value.(cepCacheEntry).cep)
+}
+
+// Flush removes all the entries of the cache and returns how many there were.
+func (c *CachingCEPClient) Flush() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := len(c.entries)
+	c.entries = make(map[string]*list.Element)
+	c.recentlyUsed.Init()
+	return removed
+}
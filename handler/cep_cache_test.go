@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/leoseiji/go-tracing/clock"
+	"github.com/leoseiji/go-tracing/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSingleflightDeduplicatesConcurrentCEPLookups(t *testing.T) {
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	var viaCEPCalls atomic.Int64
+	release := make(chan struct{})
+	// slowViaCEP holds the lookups until all the requests are in flight.
+	slowViaCEP := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		viaCEPCalls.Add(1)
+		<-release
+		viaCEP.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(slowViaCEP.Close)
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewCachingCEPClient(NewViaCEPClient(ViaCEPConfig{BaseURL: slowViaCEP.URL}), 0, 0, nil),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+	})
+	router := http.NewServeMux()
+	router.HandleFunc("GET /weather/{cep}", serviceB.GetWeatherHandler)
+
+	const requests = 10
+	responses := make([]*httptest.ResponseRecorder, requests)
+	var wg sync.WaitGroup
+	for i := range responses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = httptest.NewRecorder()
+			router.ServeHTTP(responses[i], httptest.NewRequest(http.MethodGet, "/weather/06233903", nil))
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), viaCEPCalls.Load())
+	for _, rr := range responses {
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, weatherResponseFixture, rr.Body.String())
+	}
+}
+
+// countingCEPClient finds every CEP, counting the lookups of each.
+type countingCEPClient struct {
+	mu      sync.Mutex
+	lookups map[string]int
+}
+
+func (c *countingCEPClient) GetLocation(_ context.Context, cep string) (*dto.Location, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lookups[cep]++
+	return &dto.Location{CEP: cep, Location: "Osasco"}, nil
+}
+
+func TestCachingCEPClientEvictsLeastRecentlyUsed(t *testing.T) {
+	next := &countingCEPClient{lookups: map[string]int{}}
+	clk := clock.NewFakeClock(time.Now())
+	cache := NewCachingCEPClient(next, time.Hour, 2, clk)
+	ctx := context.Background()
+
+	for _, cep := range []string{"06233903", "01310100", "06233903", "90010000"} {
+		_, err := cache.GetLocation(ctx, cep)
+		assert.NoError(t, err)
+	}
+	// 01310100 was the least recently used when 90010000 was cached.
+	for _, cep := range []string{"06233903", "90010000", "01310100"} {
+		_, err := cache.GetLocation(ctx, cep)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, map[string]int{"06233903": 1, "01310100": 2, "90010000": 1}, next.lookups)
+	assert.Equal(t, 2, cache.Flush())
+
+	_, err := cache.GetLocation(ctx, "06233903")
+	assert.NoError(t, err)
+	clk.Advance(time.Hour)
+	_, err = cache.GetLocation(ctx, "06233903")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, next.lookups["06233903"])
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/leoseiji/go-tracing/dnscache"
+	"github.com/leoseiji/go-tracing/dto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// DefaultViaCEPBaseURL is the ViaCEP API used when ViaCEPConfig.BaseURL is empty.
+const DefaultViaCEPBaseURL = "https://viacep.com.br"
+
+// DefaultViaCEPTimeout bounds a ViaCEP lookup when ViaCEPConfig.Timeout is not set.
+const DefaultViaCEPTimeout = 5 * time.Second
+
+// CEPClient resolves a CEP into its location.
+type CEPClient interface {
+	GetLocation(ctx context.Context, cep string) (*dto.Location, error)
+}
+
+// ViaCEPFormat is the response format requested from ViaCEP.
+type ViaCEPFormat int
+
+const (
+	FormatJSON ViaCEPFormat = iota
+	FormatXML
+)
+
+// path returns the ViaCEP path segment selecting the format.
+func (f ViaCEPFormat) path() string {
+	if f == FormatXML {
+		return "xml"
+	}
+	return "json"
+}
+
+type ViaCEPConfig struct {
+	// BaseURL of the ViaCEP API, without a trailing slash. Defaults to DefaultViaCEPBaseURL.
+	// Point it at a local stub or a staging environment to run without reaching viacep.com.br.
+	BaseURL string
+	// Format of the ViaCEP responses. Defaults to FormatJSON.
+	Format ViaCEPFormat
+	// Timeout bounds each lookup, including reading the response. Defaults to DefaultViaCEPTimeout.
+	Timeout time.Duration
+	// HTTPClient used for the requests. Defaults to a client resolving hosts through Resolver
+	// and sending the User-Agent of the service.
+	HTTPClient *http.Client
+	// Resolver used for DNS lookups by the default HTTPClient, whose answers are cached.
+	// Defaults to net.DefaultResolver.
+	Resolver dnscache.Resolver
+	// DNSCacheTTL is how long the default HTTPClient caches the addresses of the hosts. Defaults to dnscache.DefaultTTL.
+	DNSCacheTTL time.Duration
+	// Timeouts of the connections and responses of the default HTTPClient, within Timeout.
+	Timeouts Timeouts
+	// TLSConfig of the default HTTPClient, see NewUpstreamTLSConfig. Defaults to the system settings.
+	TLSConfig *tls.Config
+	// MeterProvider records the lookup latencies. Defaults to the global MeterProvider.
+	MeterProvider metric.MeterProvider
+}
+
+// ViaCEPClient is a CEPClient backed by the ViaCEP HTTP API.
+type ViaCEPClient struct {
+	baseURL    string
+	format     ViaCEPFormat
+	timeout    time.Duration
+	httpClient *http.Client
+
+	lookupDuration metric.Float64Histogram
+}
+
+// NewViaCEPClient creates a ViaCEPClient.
+// cfg.BaseURL must not end with a trailing slash, the lookup paths are appended to it.
+func NewViaCEPClient(cfg ViaCEPConfig) *ViaCEPClient {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultViaCEPBaseURL
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Transport: newUserAgentTransport(newTransport(cfg.Resolver, cfg.DNSCacheTTL, cfg.Timeouts, cfg.TLSConfig))}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultViaCEPTimeout
+	}
+	return &ViaCEPClient{
+		baseURL:        cfg.BaseURL,
+		format:         cfg.Format,
+		timeout:        cfg.Timeout,
+		httpClient:     cfg.HTTPClient,
+		lookupDuration: newLatencyHistogram(cfg.MeterProvider, "cep_lookup_duration_ms", "Duration of the CEP lookups on ViaCEP."),
+	}
+}
+
+// Ping reports whether ViaCEP is reachable.
+func (c *ViaCEPClient) Ping(ctx context.Context) error {
+	return ping(ctx, c.httpClient, c.baseURL)
+}
+
+func (c *ViaCEPClient) GetLocation(ctx context.Context, cep string) (*dto.Location, error) {
+	tracer := otel.Tracer("weather-service-b-get-location-by-cep")
+	_, span := tracer.Start(ctx, "getLocationByCEP")
+	defer span.End()
+	start := time.Now()
+	defer func() {
+		c.lookupDuration.Record(ctx, durationInMilliseconds(time.Since(start)))
+	}()
+
+	// A timeout is reported as context.DeadlineExceeded.
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/ws/%s/%s/", c.baseURL, cep, c.format.path())
+	if err := ctx.Err(); err != nil {
+		return nil, canceledError(ctx, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		log.Printf("error creating ViaCEP request. Err:%s", err.Error())
+		return nil, err
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		log.Printf("error executing ViaCEP request%s. Err:%s", timeoutSuffix(ctx, err), err.Error())
+		return nil, canceledError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+
+	case http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			log.Printf("error while reading ViaCEP result. Err:%s", err.Error())
+			return nil, err
+		}
+
+		var location dto.Location
+		if c.format == FormatXML {
+			err = xml.Unmarshal(body, &location)
+		} else {
+			err = json.Unmarshal(body, &location)
+		}
+		if err != nil {
+			log.Printf("error while converting ViaCEP result. Err:%s", err.Error())
+			return nil, err
+		}
+		if location.Erro || location.CEP == "" {
+			return nil, ErrCEPNotFound
+		}
+		return &location, nil
+
+	case http.StatusNotFound:
+		return nil, ErrCEPNotFound
+
+	default:
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+}
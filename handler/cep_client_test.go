@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// CountingDNSResolver resolves every host to the loopback address and counts the lookups.
+type CountingDNSResolver struct {
+	lookups atomic.Int64
+}
+
+func (r *CountingDNSResolver) LookupHost(_ context.Context, _ string) ([]string, error) {
+	r.lookups.Add(1)
+	return []string{"127.0.0.1"}, nil
+}
+
+func TestViaCEPClientGetLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   ViaCEPFormat
+		body     string
+		location string
+		err      error
+	}{
+		{name: "Known CEP", body: `{"cep": "06233-903", "localidade": "Osasco"}`, location: "Osasco"},
+		{name: "Erro flag", body: `{"erro": true}`, err: ErrCEPNotFound},
+		{name: "Erro flag with CEP", body: `{"cep": "06233-903", "erro": true}`, err: ErrCEPNotFound},
+		{
+			name:     "Known CEP in XML",
+			format:   FormatXML,
+			body:     `<?xml version="1.0" encoding="UTF-8"?><xmlcep><cep>06233-903</cep><localidade>Osasco</localidade></xmlcep>`,
+			location: "Osasco",
+		},
+		{
+			name:   "Erro flag in XML",
+			format: FormatXML,
+			body:   `<?xml version="1.0" encoding="UTF-8"?><xmlcep><erro>true</erro></xmlcep>`,
+			err:    ErrCEPNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			viaCEP := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				w.Write([]byte(tt.body))
+			}))
+			defer viaCEP.Close()
+
+			client := NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL, Format: tt.format})
+			location, err := client.GetLocation(context.Background(), "06233903")
+
+			assert.Equal(t, "/ws/06233903/"+tt.format.path()+"/", path)
+			assert.ErrorIs(t, err, tt.err)
+			if tt.err == nil {
+				assert.Equal(t, tt.location, location.Location)
+			}
+		})
+	}
+}
+
+func TestViaCEPClientHandlesErroBooleanField(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		location string
+		err      error
+	}{
+		{name: "Erro as a string", body: `{"erro": "true"}`, err: ErrCEPNotFound},
+		{name: "Erro as a boolean", body: `{"erro": true}`, err: ErrCEPNotFound},
+		{name: "Known CEP", body: `{"cep": "06233-903", "localidade": "Osasco", "erro": "false"}`, location: "Osasco"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCEP := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer viaCEP.Close()
+
+			client := NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL})
+			location, err := client.GetLocation(context.Background(), "06233903")
+
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.location, location.Location)
+				assert.False(t, location.Erro)
+			}
+		})
+	}
+}
+
+func TestLocationClientPingFailsWhenViaCEPDown(t *testing.T) {
+	newViaCEP := func(status int) string {
+		viaCEP := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(viaCEP.Close)
+		return viaCEP.URL
+	}
+	// Nothing listens on the address of a closed server.
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	t.Run("Unavailable", func(t *testing.T) {
+		err := NewViaCEPClient(ViaCEPConfig{BaseURL: newViaCEP(http.StatusServiceUnavailable)}).Ping(context.Background())
+		assert.EqualError(t, err, "unexpected status code: 503")
+	})
+	t.Run("Available", func(t *testing.T) {
+		err := NewViaCEPClient(ViaCEPConfig{BaseURL: newViaCEP(http.StatusOK)}).Ping(context.Background())
+		assert.NoError(t, err)
+	})
+	t.Run("Connection refused", func(t *testing.T) {
+		err := NewViaCEPClient(ViaCEPConfig{BaseURL: closed.URL}).Ping(context.Background())
+		var opErr *net.OpError
+		assert.ErrorAs(t, err, &opErr)
+	})
+}
+
+func TestDNSCachingReducesLookups(t *testing.T) {
+	viaCEP := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"cep": "06233-903", "localidade": "Osasco"}`))
+	}))
+	// Without keep-alives every request dials, and so resolves, the host again.
+	viaCEP.Config.SetKeepAlivesEnabled(false)
+	viaCEP.Start()
+	defer viaCEP.Close()
+
+	resolver := &CountingDNSResolver{}
+	client := NewViaCEPClient(ViaCEPConfig{
+		BaseURL:  strings.Replace(viaCEP.URL, "127.0.0.1", "viacep.test", 1),
+		Resolver: resolver,
+	})
+
+	for i := 0; i < 100; i++ {
+		_, err := client.GetLocation(context.Background(), "06233903")
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	assert.Equal(t, int64(1), resolver.lookups.Load())
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DeadLetter describes an upstream call that failed for good, once all its attempts were made.
+type DeadLetter struct {
+	// Service is the name of the upstream, such as weatherapi,
+	// or cep for the CEP lookups failing on every CEP upstream, see FallbackCEPClient.
+	Service  string
+	CEP      string
+	Location string
+	// URL of the call, without its credentials. Empty when several upstreams were called.
+	URL            string
+	Attempts       int
+	FirstError     error
+	LastError      error
+	FirstAttemptAt time.Time
+	LastAttemptAt  time.Time
+}
+
+// DeadLetterLogger writes a JSON record of every failed upstream call,
+// a permanent audit trail of the failed lookups meant to be ingested by tools such as ELK or Splunk.
+// A nil *DeadLetterLogger logs nothing.
+type DeadLetterLogger struct {
+	logger *slog.Logger
+}
+
+// NewDeadLetterLogger creates a DeadLetterLogger writing to w, which defaults to os.Stderr.
+func NewDeadLetterLogger(w io.Writer) *DeadLetterLogger {
+	if w == nil {
+		w = os.Stderr
+	}
+	return &DeadLetterLogger{logger: slog.New(slog.NewJSONHandler(w, nil))}
+}
+
+// Record logs d at Error level.
+// The trace ID is taken from the span in ctx for correlation.
+func (l *DeadLetterLogger) Record(ctx context.Context, d DeadLetter) {
+	if l == nil {
+		return
+	}
+	l.logger.ErrorContext(ctx, "upstream call failed",
+		slog.String("service", d.Service),
+		slog.String("cep", d.CEP),
+		slog.String("location", d.Location),
+		slog.String("url", d.URL),
+		slog.Int("attempts", d.Attempts),
+		slog.String("first_error", errorString(d.FirstError)),
+		slog.String("last_error", errorString(d.LastError)),
+		slog.Time("first_attempt_at", d.FirstAttemptAt),
+		slog.Time("last_attempt_at", d.LastAttemptAt),
+		slog.String("trace_id", trace.SpanContextFromContext(ctx).TraceID().String()),
+	)
+}
+
+// recordSingleAttempt records the failure err of a call attempted once, at start.
+func (l *DeadLetterLogger) recordSingleAttempt(ctx context.Context, d DeadLetter, start time.Time, err error) {
+	d.Attempts = 1
+	d.FirstError, d.LastError = err, err
+	d.FirstAttemptAt, d.LastAttemptAt = start, start
+	l.Record(ctx, d)
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLetterLoggerRecordsFailedLookups(t *testing.T) {
+	tests := []struct {
+		name             string
+		cep              string
+		viaCEPStatus     int
+		apiCEPStatus     int
+		weatherAPIStatus int
+		record           map[string]any
+	}{
+		{
+			name:             "ViaCEP and APICEP failure",
+			cep:              "06233903",
+			viaCEPStatus:     http.StatusInternalServerError,
+			apiCEPStatus:     http.StatusBadGateway,
+			weatherAPIStatus: http.StatusBadGateway,
+			record: map[string]any{
+				"service":     "cep",
+				"cep":         "06233903",
+				"attempts":    float64(2),
+				"first_error": "unexpected status code: 500",
+				"last_error":  "unexpected status code: 502",
+			},
+		},
+		{
+			name:             "WeatherAPI failure",
+			cep:              "06233903",
+			viaCEPStatus:     http.StatusOK,
+			apiCEPStatus:     http.StatusOK,
+			weatherAPIStatus: http.StatusBadGateway,
+			record: map[string]any{
+				"service":     "weatherapi",
+				"location":    "Osasco",
+				"attempts":    float64(1),
+				"first_error": "unexpected status code: 502",
+				"last_error":  "unexpected status code: 502",
+			},
+		},
+		// The lookup served by APICEP did not fail, only the weather did.
+		{
+			name:             "ViaCEP failure served by APICEP",
+			cep:              "06233903",
+			viaCEPStatus:     http.StatusInternalServerError,
+			apiCEPStatus:     http.StatusOK,
+			weatherAPIStatus: http.StatusBadGateway,
+			record:           map[string]any{"service": "weatherapi"},
+		},
+		{name: "Unknown CEP", cep: "12345678", viaCEPStatus: http.StatusOK, apiCEPStatus: http.StatusOK, weatherAPIStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			deadLetters := NewDeadLetterLogger(&out)
+			viaCEP, weatherAPI := newUpstreams(t, tt.viaCEPStatus, tt.weatherAPIStatus)
+			apiCEP := newAPICEP(t)
+			if tt.apiCEPStatus != http.StatusOK {
+				apiCEP = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(tt.apiCEPStatus)
+				}))
+				t.Cleanup(apiCEP.Close)
+			}
+			serviceB := NewServiceBHandler(ServiceBConfig{
+				CEPClient: NewFallbackCEPClient(
+					NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+					NewAPICEPClient(APICEPConfig{BaseURL: apiCEP.URL}),
+					deadLetters,
+				),
+				WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "secret", DeadLetterLogger: deadLetters}),
+			})
+
+			_, err := serviceB.GetWeather(context.Background(), tt.cep)
+
+			assert.Error(t, err)
+			if tt.record == nil {
+				assert.Empty(t, out.String())
+				return
+			}
+			// A single record is expected, the decoder fails on a second one.
+			var record map[string]any
+			if !assert.NoError(t, json.Unmarshal(out.Bytes(), &record)) {
+				return
+			}
+			for key, value := range tt.record {
+				assert.Equal(t, value, record[key], key)
+			}
+			assert.Equal(t, "upstream call failed", record["msg"])
+			assert.NotEmpty(t, record["first_attempt_at"])
+			assert.Contains(t, record, "trace_id")
+			assert.NotContains(t, record["url"], "secret")
+		})
+	}
+}
+
+func TestDeadLetterLoggerRedactsTheAPIKeyOfUnreachableWeatherAPI(t *testing.T) {
+	var out bytes.Buffer
+	deadLetters := NewDeadLetterLogger(&out)
+	_, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	weatherAPI.Close()
+	provider := NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "secret", DeadLetterLogger: deadLetters})
+
+	_, err := provider.GetWeather(context.Background(), "Osasco")
+
+	if assert.Error(t, err) {
+		assert.NotContains(t, err.Error(), "secret")
+	}
+	var record map[string]any
+	if !assert.NoError(t, json.Unmarshal(out.Bytes(), &record)) {
+		return
+	}
+	for _, key := range []string{"url", "first_error", "last_error"} {
+		assert.Contains(t, record[key], "/v1/current.json?q=Osasco", key)
+		assert.NotContains(t, record[key], "secret", key)
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/leoseiji/go-tracing/docs"
+)
+
+// swaggerUIURL serves the assets of Swagger UI rendered by /docs.
+const swaggerUIURL = "https://unpkg.com/swagger-ui-dist@5"
+
+// swaggerUIPage renders the specification served by /openapi.json.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>go-tracing API</title>
+<link rel="stylesheet" href="` + swaggerUIURL + `/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="` + swaggerUIURL + `/swagger-ui-bundle.js"></script>
+<script src="/docs/init.js"></script>
+</body>
+</html>
+`
+
+// swaggerUIInit starts Swagger UI. It is served apart from the page, so that no inline script is allowed.
+const swaggerUIInit = `window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});
+`
+
+// NewOpenAPIHandler serves the OpenAPI specification of docs/openapi.yaml in JSON.
+func NewOpenAPIHandler() http.HandlerFunc {
+	spec, err := docs.OpenAPIJSON()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			log.Printf("error converting the OpenAPI specification. Err:%s", err.Error())
+			http.Error(w, ErrInternalServerError.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	}
+}
+
+// docsCSP is the Content-Security-Policy of /docs, replacing the one of middleware.SecurityHeadersMiddleware
+// to allow Swagger UI.
+const docsCSP = "default-src 'none'; script-src 'self' https://unpkg.com; style-src https://unpkg.com; img-src 'self' data:; connect-src 'self'"
+
+// DocsHandler renders the OpenAPI specification with Swagger UI, loaded from swaggerUIURL.
+func DocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Security-Policy", docsCSP)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(swaggerUIPage))
+}
+
+// DocsInitHandler serves the script starting Swagger UI on the page of DocsHandler.
+func DocsInitHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Security-Policy", docsCSP)
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Write([]byte(swaggerUIInit))
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPIHandlerServesSpec(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewOpenAPIHandler()(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var spec struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if !assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &spec)) {
+		return
+	}
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+	// Every route of the API is documented.
+	for path, method := range map[string]string{
+		"/v1/weather-service-a":       "post",
+		"/v1/weather/batch":           "post",
+		"/v1/weather-service-b/{cep}": "get",
+		"/v1/weather/{cep}/forecast":  "get",
+		"/health":                     "get",
+		"/version":                    "get",
+	} {
+		assert.Contains(t, spec.Paths[path], method, path)
+	}
+}
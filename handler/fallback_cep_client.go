@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/leoseiji/go-tracing/dto"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// cepLookupService is the DeadLetter service of the CEP lookups, made on ViaCEP then APICEP.
+const cepLookupService = "cep"
+
+// FallbackCEPClient is a CEPClient looking up CEPs on a secondary CEPClient when the primary one fails,
+// e.g. during an outage of ViaCEP.
+// CEPs not found by the primary CEPClient are not looked up again.
+type FallbackCEPClient struct {
+	primary     CEPClient
+	secondary   CEPClient
+	deadLetters *DeadLetterLogger
+}
+
+// NewFallbackCEPClient creates a FallbackCEPClient recording the lookups failing on both CEPClients in deadLetters,
+// which may be nil.
+func NewFallbackCEPClient(primary, secondary CEPClient, deadLetters *DeadLetterLogger) *FallbackCEPClient {
+	return &FallbackCEPClient{primary: primary, secondary: secondary, deadLetters: deadLetters}
+}
+
+// GetLocation adds the event cep.fallback to the span in ctx when the secondary CEPClient is used.
+func (c *FallbackCEPClient) GetLocation(ctx context.Context, cep string) (*dto.Location, error) {
+	firstAttemptAt := time.Now()
+	location, err := c.primary.GetLocation(ctx, cep)
+	if err == nil || errors.Is(err, ErrCEPNotFound) || ctx.Err() != nil {
+		return location, err
+	}
+
+	log.Printf("error looking up CEP on the primary client, falling back to the secondary one. Err:%s", err.Error())
+	trace.SpanFromContext(ctx).AddEvent("cep.fallback", trace.WithAttributes(cepFallbackKey.Bool(true)))
+	lastAttemptAt := time.Now()
+	location, lastErr := c.secondary.GetLocation(ctx, cep)
+	if lastErr != nil && !errors.Is(lastErr, ErrCEPNotFound) && !errors.Is(lastErr, ErrRequestCanceled) {
+		c.deadLetters.Record(ctx, DeadLetter{
+			Service:        cepLookupService,
+			CEP:            cep,
+			Attempts:       2,
+			FirstError:     err,
+			LastError:      lastErr,
+			FirstAttemptAt: firstAttemptAt,
+			LastAttemptAt:  lastAttemptAt,
+		})
+	}
+	return location, lastErr
+}
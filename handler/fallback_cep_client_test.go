@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+)
+
+// newAPICEP starts a stub APICEP server knowing only the CEP 06233903.
+func newAPICEP(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	apiCEP := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/file/apicep/06233-903.json" {
+			w.Write([]byte(`{"status": 404, "ok": false, "message": "CEP não encontrado", "statusText": "not_found"}`))
+			return
+		}
+		w.Write([]byte(`{"status": 200, "ok": true, "code": "06233-903", "state": "SP", "city": "Osasco", "district": "Piratininga", "address": "Rua Iguaçu", "statusText": "ok"}`))
+	}))
+	t.Cleanup(apiCEP.Close)
+	return apiCEP
+}
+
+func TestAPICEPClientGetLocation(t *testing.T) {
+	apiCEP := NewAPICEPClient(APICEPConfig{BaseURL: newAPICEP(t).URL})
+
+	location, err := apiCEP.GetLocation(context.Background(), "06233903")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "06233-903", location.CEP)
+		assert.Equal(t, "Osasco", location.Location)
+	}
+	_, err = apiCEP.GetLocation(context.Background(), "12345678")
+	assert.ErrorIs(t, err, ErrCEPNotFound)
+	_, err = apiCEP.GetLocation(context.Background(), "0623")
+	assert.ErrorIs(t, err, ErrCEPInvalid)
+}
+
+func TestFallbackCEPClient(t *testing.T) {
+	apiCEP := newAPICEP(t)
+	tests := []struct {
+		name         string
+		viaCEPStatus int
+		cep          string
+		location     string
+		err          error
+		fallback     bool
+	}{
+		{name: "ViaCEP answers", viaCEPStatus: http.StatusOK, cep: "06233903", location: "Osasco"},
+		{name: "ViaCEP does not know the CEP", viaCEPStatus: http.StatusOK, cep: "12345678", err: ErrCEPNotFound},
+		{name: "ViaCEP fails", viaCEPStatus: http.StatusInternalServerError, cep: "06233903", location: "Osasco", fallback: true},
+		{name: "ViaCEP fails and APICEP does not know the CEP", viaCEPStatus: http.StatusInternalServerError, cep: "12345678", err: ErrCEPNotFound, fallback: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := newSpanRecorder(t)
+			viaCEP, _ := newUpstreams(t, tt.viaCEPStatus, http.StatusOK)
+			cepClient := NewFallbackCEPClient(
+				NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+				NewAPICEPClient(APICEPConfig{BaseURL: apiCEP.URL}),
+				nil,
+			)
+
+			ctx, span := otel.Tracer("test").Start(context.Background(), "GetWeatherHandler")
+			location, err := cepClient.GetLocation(ctx, tt.cep)
+			span.End()
+
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tt.location, location.Location)
+			}
+			handlerSpan := findSpan(recorder.Ended(), "GetWeatherHandler")
+			if !assert.NotNil(t, handlerSpan) {
+				return
+			}
+			var fallback bool
+			for _, event := range handlerSpan.Events() {
+				if event.Name == "cep.fallback" {
+					fallback = true
+					assert.Contains(t, event.Attributes, cepFallbackKey.Bool(true))
+				}
+			}
+			assert.Equal(t, tt.fallback, fallback)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/leoseiji/go-tracing/dto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultForecastDays is the number of forecast days when the days query parameter is not set.
+	DefaultForecastDays = 3
+	// MaxForecastDays is the most days WeatherAPI forecasts.
+	MaxForecastDays = 14
+)
+
+var ErrForecastDaysInvalid = fmt.Errorf("days must be an integer between 1 and %d", MaxForecastDays)
+
+// GetWeatherForecastHandler serves the daily forecast of the city of a CEP.
+// The optional days query parameter selects how many days, from 1 to MaxForecastDays.
+func (h *ServiceBHandler) GetWeatherForecastHandler(w http.ResponseWriter, r *http.Request) {
+	carrier := propagation.HeaderCarrier(r.Header)
+	ctx := r.Context()
+	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+	tracer := otel.Tracer("weather-service-b")
+	ctx, span := tracer.Start(ctx, "GetWeatherForecastHandler")
+	defer span.End()
+
+	days := DefaultForecastDays
+	if daysParam := r.URL.Query().Get("days"); daysParam != "" {
+		var err error
+		days, err = strconv.Atoi(daysParam)
+		if err != nil || days < 1 || days > MaxForecastDays {
+			http.Error(w, ErrForecastDaysInvalid.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	forecastResponse, err := h.GetForecast(ctx, r.PathValue("cep"), days)
+	if errors.Is(err, ErrCEPInvalid) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if errors.Is(err, ErrCEPNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, ErrRequestCanceled) {
+		log.Printf("request canceled by the client: %s", err)
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrServiceUnavailable) {
+		span.SetStatus(codes.Error, err.Error())
+		http.Error(w, ErrServiceUnavailable.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		http.Error(w, ErrInternalServerError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(forecastResponse)
+}
+
+// GetForecast looks up the forecast of the city of cep for the next days,
+// recording its attributes on the span in ctx.
+// It returns ErrCEPInvalid or ErrCEPNotFound for CEPs that can not be served,
+// and ErrServiceUnavailable when no ForecastProvider is configured.
+func (h *ServiceBHandler) GetForecast(ctx context.Context, cep string, days int) (*dto.CEPForecastResponse, error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(forecastDaysKey.Int(days))
+
+	if h.forecastProvider == nil {
+		return nil, fmt.Errorf("%w: no forecast provider", ErrServiceUnavailable)
+	}
+	if !h.cepValidation.isCepValid(ctx, cep) {
+		return nil, ErrCEPInvalid
+	}
+	span.SetAttributes(cepKey.String(cep))
+
+	location, err := h.cepClient.GetLocation(ctx, cep)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(locationNameKey.String(location.Location))
+
+	forecast, err := h.forecastProvider.GetForecast(ctx, location.Location, days)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewCEPForecastResponse(location, forecast)
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/leoseiji/go-tracing/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+// forecastAPIFixture answers the WeatherAPI forecast endpoint with days forecast days,
+// starting on 2024-06-17 at 20°C and warming up one degree a day.
+func forecastAPIFixture(days string) string {
+	n, _ := strconv.Atoi(days)
+	forecast := dto.Forecast{}
+	for i := 0; i < n; i++ {
+		forecast.Forecast.ForecastDay = append(forecast.Forecast.ForecastDay, dto.ForecastDay{
+			Date: fmt.Sprintf("2024-06-%d", 17+i),
+			Day: dto.ForecastDayWeather{
+				MaxTempC:          float64(20 + i),
+				MinTempC:          float64(10 + i),
+				DailyChanceOfRain: 40,
+				Condition:         dto.WeatherCondition{Text: "Sunny"},
+			},
+		})
+	}
+	body, _ := json.Marshal(forecast)
+	return string(body)
+}
+
+func TestGetWeatherForecastHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		days   int
+	}{
+		{name: "Default days", path: "/weather/06233903/forecast", status: http.StatusOK, days: DefaultForecastDays},
+		{name: "One day", path: "/weather/06233903/forecast?days=1", status: http.StatusOK, days: 1},
+		{name: "Most days", path: "/weather/06233903/forecast?days=14", status: http.StatusOK, days: 14},
+		{name: "Zero days", path: "/weather/06233903/forecast?days=0", status: http.StatusBadRequest},
+		{name: "Too many days", path: "/weather/06233903/forecast?days=15", status: http.StatusBadRequest},
+		{name: "Days not a number", path: "/weather/06233903/forecast?days=week", status: http.StatusBadRequest},
+		{name: "Invalid CEP", path: "/weather/invalid/forecast", status: http.StatusUnprocessableEntity},
+		{name: "Unknown CEP", path: "/weather/12345678/forecast", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := newSpanRecorder(t)
+			viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+			weatherProvider := NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"})
+			serviceB := NewServiceBHandler(ServiceBConfig{
+				CEPClient:        NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+				WeatherProvider:  weatherProvider,
+				ForecastProvider: weatherProvider,
+			})
+			router := http.NewServeMux()
+			router.HandleFunc("GET /weather/{cep}/forecast", serviceB.GetWeatherForecastHandler)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+			var forecastResponse dto.CEPForecastResponse
+			if !assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &forecastResponse)) {
+				return
+			}
+			assert.Equal(t, "Osasco", forecastResponse.Location)
+			if assert.Len(t, forecastResponse.Days, tt.days) {
+				assert.Equal(t, dto.DailyForecastResponse{
+					Date:                       "2024-06-17",
+					MaxTemperatureInCelsius:    20,
+					MaxTemperatureInFahrenheit: 68,
+					MinTemperatureInCelsius:    10,
+					MinTemperatureInFahrenheit: 50,
+					ChanceOfRain:               40,
+					Condition:                  "Sunny",
+				}, forecastResponse.Days[0])
+			}
+
+			spans := recorder.Ended()
+			handlerSpan := findSpan(spans, "GetWeatherForecastHandler")
+			locationSpan := findSpan(spans, "getLocationByCEP")
+			forecastSpan := findSpan(spans, "getForecastByLocation")
+			if !assert.NotNil(t, handlerSpan) || !assert.NotNil(t, locationSpan) || !assert.NotNil(t, forecastSpan) {
+				return
+			}
+			assert.Contains(t, handlerSpan.Attributes(), forecastDaysKey.Int(tt.days))
+			assert.Contains(t, forecastSpan.Attributes(), forecastDaysKey.Int(tt.days))
+			assert.Equal(t, handlerSpan.SpanContext().SpanID(), locationSpan.Parent().SpanID())
+			assert.Equal(t, handlerSpan.SpanContext().SpanID(), forecastSpan.Parent().SpanID())
+		})
+	}
+}
+
+func TestGetWeatherForecastHandlerWithoutForecastProvider(t *testing.T) {
+	viaCEP, _ := newUpstreams(t, http.StatusOK, http.StatusOK)
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: &MockWeatherProvider{},
+	})
+	router := http.NewServeMux()
+	router.HandleFunc("GET /weather/{cep}/forecast", serviceB.GetWeatherForecastHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/weather/06233903/forecast", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
+
+func TestWeatherAPIProviderGetForecastNullBody(t *testing.T) {
+	weatherAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`null`))
+	}))
+	t.Cleanup(weatherAPI.Close)
+	provider := NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"})
+
+	forecast, err := provider.GetForecast(context.Background(), "Osasco", 1)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, forecast)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/leoseiji/go-tracing/dto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// ForecastProvider fetches the daily forecast of a location name for the next days.
+type ForecastProvider interface {
+	GetForecast(ctx context.Context, location string, days int) (*dto.Forecast, error)
+}
+
+// GetForecast calls the WeatherAPI forecast endpoint, which returns up to 14 days.
+func (p *WeatherAPIProvider) GetForecast(ctx context.Context, location string, days int) (*dto.Forecast, error) {
+	tracer := otel.Tracer("weather-service-b-get-forecast-by-location")
+	_, span := tracer.Start(ctx, "getForecastByLocation")
+	defer span.End()
+	span.SetAttributes(forecastDaysKey.Int(days))
+
+	reqUrl := fmt.Sprintf("%s/v1/forecast.json?key=%s&q=%s&days=%d", p.baseURL, url.QueryEscape(p.apiKey), url.QueryEscape(location), days)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqUrl, nil)
+	if err != nil {
+		log.Printf("error creating weatherAPI forecast request. Err:%s", err.Error())
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		err = redactURLError(err, fmt.Sprintf("%s/v1/forecast.json?q=%s&days=%d", p.baseURL, url.QueryEscape(location), days))
+		log.Printf("error executing weatherAPI forecast request%s. Err:%s", timeoutSuffix(ctx, err), err.Error())
+		return nil, canceledError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		log.Printf("error while getting weatherAPI forecast result. Status: %s, Body: %s", resp.Status, string(body))
+
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// A null body decodes into an empty forecast rather than a nil one.
+	var forecast dto.Forecast
+	if err = json.NewDecoder(resp.Body).Decode(&forecast); err != nil {
+		log.Printf("error while converting weatherAPI forecast result. Err:%s", err.Error())
+		return nil, err
+	}
+	return &forecast, nil
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/leoseiji/go-tracing/dto"
+	"github.com/leoseiji/go-tracing/middleware"
+	weatherpb "github.com/leoseiji/go-tracing/proto/gen"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCServer serves the WeatherService gRPC contract of service B,
+// sharing the business logic of ServiceBHandler.
+type GRPCServer struct {
+	weatherpb.UnimplementedWeatherServiceServer
+	serviceB *ServiceBHandler
+}
+
+func NewGRPCServer(serviceB *ServiceBHandler) *GRPCServer {
+	return &GRPCServer{serviceB: serviceB}
+}
+
+type GRPCServerConfig struct {
+	// InternalSecret authenticates the RPCs of service A, see middleware.InternalAuthUnaryServerInterceptor.
+	// The server is open to any caller when it is empty.
+	InternalSecret []byte
+	// InternalSignatureSkew is the skew tolerated on the signatures. Defaults to middleware.DefaultSignatureSkew.
+	InternalSignatureSkew time.Duration
+}
+
+// NewWeatherGRPCServer creates a gRPC server serving the WeatherService of serviceB.
+// Its RPCs are traced by the OTel instrumentation, which extracts the trace context of the callers.
+func NewWeatherGRPCServer(serviceB *ServiceBHandler, cfg GRPCServerConfig) *grpc.Server {
+	if cfg.InternalSignatureSkew <= 0 {
+		cfg.InternalSignatureSkew = middleware.DefaultSignatureSkew
+	}
+	options := []grpc.ServerOption{grpc.StatsHandler(otelgrpc.NewServerHandler())}
+	if len(cfg.InternalSecret) > 0 {
+		options = append(options, grpc.UnaryInterceptor(middleware.InternalAuthUnaryServerInterceptor(cfg.InternalSecret, cfg.InternalSignatureSkew)))
+	}
+	server := grpc.NewServer(options...)
+	weatherpb.RegisterWeatherServiceServer(server, NewGRPCServer(serviceB))
+	return server
+}
+
+func (s *GRPCServer) GetWeather(ctx context.Context, req *weatherpb.WeatherRequest) (*weatherpb.WeatherResponse, error) {
+	tracer := otel.Tracer("weather-service-b")
+	ctx, span := tracer.Start(ctx, "GetWeatherGRPC")
+	defer span.End()
+
+	weatherResponse, err := s.serviceB.GetWeather(ctx, req.GetCep())
+	if errors.Is(err, ErrCEPInvalid) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, ErrCEPNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, ErrRequestCanceled) {
+		return nil, status.Error(codes.Canceled, err.Error())
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		span.SetStatus(otelcodes.Error, err.Error())
+		return nil, status.Error(codes.Unavailable, ErrServiceUnavailable.Error())
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+		return nil, status.Error(codes.Internal, ErrInternalServerError.Error())
+	}
+	return newWeatherResponseMessage(weatherResponse), nil
+}
+
+// GetForecast returns the forecast of the next DefaultForecastDays,
+// since WeatherRequest does not carry the number of days.
+func (s *GRPCServer) GetForecast(ctx context.Context, req *weatherpb.WeatherRequest) (*weatherpb.ForecastResponse, error) {
+	tracer := otel.Tracer("weather-service-b")
+	ctx, span := tracer.Start(ctx, "GetForecastGRPC")
+	defer span.End()
+
+	forecastResponse, err := s.serviceB.GetForecast(ctx, req.GetCep(), DefaultForecastDays)
+	if errors.Is(err, ErrCEPInvalid) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, ErrCEPNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, ErrRequestCanceled) {
+		return nil, status.Error(codes.Canceled, err.Error())
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrServiceUnavailable) {
+		span.SetStatus(otelcodes.Error, err.Error())
+		return nil, status.Error(codes.Unavailable, ErrServiceUnavailable.Error())
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+		return nil, status.Error(codes.Internal, ErrInternalServerError.Error())
+	}
+
+	forecastMessage := &weatherpb.ForecastResponse{City: forecastResponse.Location}
+	for _, day := range forecastResponse.Days {
+		forecastMessage.Days = append(forecastMessage.Days, &weatherpb.DailyForecast{
+			Date:      day.Date,
+			MaxTempC:  day.MaxTemperatureInCelsius,
+			MinTempC:  day.MinTemperatureInCelsius,
+			Condition: day.Condition,
+		})
+	}
+	return forecastMessage, nil
+}
+
+func newWeatherResponseMessage(weatherResponse *dto.CEPWeatherResponse) *weatherpb.WeatherResponse {
+	return &weatherpb.WeatherResponse{
+		City:      weatherResponse.Location,
+		TempC:     weatherResponse.TemperatureInCelcius,
+		TempF:     weatherResponse.TemperatureInFahrenheit,
+		TempK:     weatherResponse.TemperatureInKelvin,
+		Condition: weatherResponse.Condition,
+	}
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	weatherpb "github.com/leoseiji/go-tracing/proto/gen"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCServerGetWeather(t *testing.T) {
+	tests := []struct {
+		name         string
+		cep          string
+		viaCEPStatus int
+		code         codes.Code
+	}{
+		{name: "Valid CEP", cep: "06233903", viaCEPStatus: http.StatusOK, code: codes.OK},
+		{name: "Invalid CEP", cep: "invalid", viaCEPStatus: http.StatusOK, code: codes.InvalidArgument},
+		{name: "Unknown CEP", cep: "12345678", viaCEPStatus: http.StatusOK, code: codes.NotFound},
+		{name: "ViaCEP error", cep: "06233903", viaCEPStatus: http.StatusInternalServerError, code: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCEP, weatherAPI := newUpstreams(t, tt.viaCEPStatus, http.StatusOK)
+			server := NewGRPCServer(NewServiceBHandler(ServiceBConfig{
+				CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+				WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+			}))
+
+			resp, err := server.GetWeather(context.Background(), &weatherpb.WeatherRequest{Cep: tt.cep})
+
+			assert.Equal(t, tt.code, status.Code(err))
+			if tt.code == codes.OK {
+				assert.Equal(t, "Osasco", resp.GetCity())
+				assert.Equal(t, 25.0, resp.GetTempC())
+				assert.Equal(t, 77.0, resp.GetTempF())
+				assert.Equal(t, 298.15, resp.GetTempK())
+				assert.Equal(t, "Sunny", resp.GetCondition())
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"crypto/tls"
+
+	"github.com/leoseiji/go-tracing/clock"
+	"github.com/leoseiji/go-tracing/dto"
+	"github.com/leoseiji/go-tracing/middleware"
+	weatherpb "github.com/leoseiji/go-tracing/proto/gen"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultServiceBGRPCTarget is the service B gRPC server used when GRPCServiceBClientConfig.Target is empty.
+const DefaultServiceBGRPCTarget = "localhost:9090"
+
+type GRPCServiceBClientConfig struct {
+	// Target is the address of the gRPC server of service B. Defaults to DefaultServiceBGRPCTarget.
+	Target string
+	// TLSConfig secures the connection. The connection is insecure when nil, which is meant for local development.
+	TLSConfig *tls.Config
+	// InternalSecret signs the RPCs for the internal authentication of service B.
+	// The RPCs are not signed when it is empty.
+	InternalSecret []byte
+	// Clock used to sign the RPCs. Defaults to clock.Real.
+	Clock clock.Clock
+}
+
+// GRPCServiceBClient is a ServiceBClient calling the WeatherService gRPC API of service B.
+type GRPCServiceBClient struct {
+	conn   *grpc.ClientConn
+	client weatherpb.WeatherServiceClient
+}
+
+// NewGRPCServiceBClient creates a client for the gRPC server of service B.
+// The connection is established lazily; call Close to release it.
+func NewGRPCServiceBClient(cfg GRPCServiceBClientConfig) (*GRPCServiceBClient, error) {
+	if cfg.Target == "" {
+		cfg.Target = DefaultServiceBGRPCTarget
+	}
+	transportCredentials := insecure.NewCredentials()
+	if cfg.TLSConfig != nil {
+		transportCredentials = credentials.NewTLS(cfg.TLSConfig)
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real{}
+	}
+	options := []grpc.DialOption{
+		grpc.WithTransportCredentials(transportCredentials),
+		// The OTel instrumentation traces the RPCs and injects their trace context.
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	}
+	if len(cfg.InternalSecret) > 0 {
+		options = append(options, grpc.WithUnaryInterceptor(middleware.InternalAuthUnaryClientInterceptor(cfg.InternalSecret, cfg.Clock)))
+	}
+	conn, err := grpc.NewClient(cfg.Target, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &GRPCServiceBClient{conn: conn, client: weatherpb.NewWeatherServiceClient(conn)}, nil
+}
+
+func (c *GRPCServiceBClient) GetWeather(ctx context.Context, cep string) (*dto.CEPWeatherResponse, error) {
+	resp, err := c.client.GetWeather(ctx, &weatherpb.WeatherRequest{Cep: cep})
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound:
+		return nil, ErrCEPNotFound
+	case codes.InvalidArgument:
+		return nil, ErrCEPInvalid
+	case codes.Canceled:
+		return nil, canceledError(ctx, err)
+	default:
+		return nil, err
+	}
+
+	// The gRPC contract only carries the city, temperatures and condition.
+	return &dto.CEPWeatherResponse{
+		Location:                resp.GetCity(),
+		TemperatureInCelcius:    resp.GetTempC(),
+		TemperatureInFahrenheit: resp.GetTempF(),
+		TemperatureInKelvin:     resp.GetTempK(),
+		Condition:               resp.GetCondition(),
+	}, nil
+}
+
+// Close closes the connection to service B.
+func (c *GRPCServiceBClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/goleak"
+)
+
+// TestMain sets up the global OpenTelemetry state the handlers rely on,
+// so tests do not depend on the order they run in.
+// Tests that assert on spans install their own provider with newSpanRecorder.
+func TestMain(m *testing.M) {
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	// Keep warnings, such as slow lookups, visible without flooding the test output.
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	os.Exit(m.Run())
+}
+
+// verifyNoLeaks fails t when goroutines started during the test are still running after it.
+// Call it before starting any server: cleanups run last in, first out,
+// so the check then runs once the stub servers and clients are closed.
+func verifyNoLeaks(t *testing.T) {
+	t.Helper()
+	ignoreRunning := goleak.IgnoreCurrent()
+	t.Cleanup(func() { goleak.VerifyNone(t, ignoreRunning) })
+}
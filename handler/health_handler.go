@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/leoseiji/go-tracing/dto"
+)
+
+// DefaultHealthCheckTimeout bounds every dependency check of the readiness probe.
+const DefaultHealthCheckTimeout = 2 * time.Second
+
+// HealthCheck reports whether a dependency of the service is reachable.
+type HealthCheck func(ctx context.Context) error
+
+// NewHealthHandler serves the health of the service.
+// With ready=true, it also runs checks, keyed by dependency name,
+// and answers 503 with the result of each check when any of them fails.
+func NewHealthHandler(checks map[string]HealthCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		health := dto.HealthResponse{Status: "ok"}
+		status := http.StatusOK
+		if r.URL.Query().Get("ready") == "true" {
+			results, healthy := runHealthChecks(r.Context(), checks)
+			if !healthy {
+				health = dto.HealthResponse{Status: "degraded", Checks: results}
+				status = http.StatusServiceUnavailable
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(health)
+	}
+}
+
+// runHealthChecks runs checks concurrently, each within DefaultHealthCheckTimeout.
+func runHealthChecks(ctx context.Context, checks map[string]HealthCheck) (results map[string]string, healthy bool) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultHealthCheckTimeout)
+	defer cancel()
+
+	type result struct {
+		name string
+		err  error
+	}
+	done := make(chan result, len(checks))
+	for name, check := range checks {
+		go func() {
+			done <- result{name: name, err: check(ctx)}
+		}()
+	}
+
+	results = make(map[string]string, len(checks))
+	healthy = true
+	for range checks {
+		res := <-done
+		if res.err != nil {
+			slog.WarnContext(ctx, "health check failed", "dependency", res.name, "error", res.err)
+			results[res.name] = "unreachable"
+			healthy = false
+			continue
+		}
+		results[res.name] = "ok"
+	}
+	return results, healthy
+}
+
+// ping reports whether url answers without a server error.
+// Client errors such as 404 still tell the upstream is up.
+func ping(ctx context.Context, client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandlerReturns200WhenUpstreamsAreHealthy(t *testing.T) {
+	tests := []struct {
+		name          string
+		viaCEPStopped bool
+		path          string
+		status        int
+		body          string
+	}{
+		{name: "Liveness", path: "/health", status: http.StatusOK, body: `{"status":"ok"}`},
+		{name: "Healthy upstreams", path: "/health?ready=true", status: http.StatusOK, body: `{"status":"ok"}`},
+		{name: "Liveness ignores the upstreams", viaCEPStopped: true, path: "/health", status: http.StatusOK, body: `{"status":"ok"}`},
+		{
+			name:          "ViaCEP stopped",
+			viaCEPStopped: true,
+			path:          "/health?ready=true",
+			status:        http.StatusServiceUnavailable,
+			body:          `{"status":"degraded","checks":{"viacep":"unreachable","weatherapi":"ok"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Every case has its own upstreams, so that stopping one does not affect the others.
+			viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+			healthHandler := NewHealthHandler(map[string]HealthCheck{
+				"viacep":     NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}).Ping,
+				"weatherapi": NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}).Ping,
+			})
+			if tt.viaCEPStopped {
+				viaCEP.Close()
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rr := httptest.NewRecorder()
+			healthHandler(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.body, rr.Body.String())
+		})
+	}
+}
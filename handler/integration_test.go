@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leoseiji/go-tracing/clock"
+	"github.com/leoseiji/go-tracing/middleware"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newServiceB starts service B against the stub upstreams and
+// reports the traceparent header of every request it receives on traceparents.
+func newServiceB(t *testing.T, viaCEP, weatherAPI *httptest.Server, traceparents chan<- string) *httptest.Server {
+	t.Helper()
+
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+	})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiPath("/weather-service-b/{cep}"), func(w http.ResponseWriter, r *http.Request) {
+		traceparents <- r.Header.Get("traceparent")
+		serviceB.GetWeatherHandler(w, r)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestServiceAToServiceB(t *testing.T) {
+	verifyNoLeaks(t)
+	recorder := newSpanRecorder(t)
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	traceparents := make(chan string, 1)
+	serviceB := newServiceB(t, viaCEP, weatherAPI, traceparents)
+	serviceA := NewServiceAHandler(ServiceAConfig{ServiceBURL: serviceB.URL})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/weather-service-a", strings.NewReader(`{"cep": "06233903"}`))
+	rr := httptest.NewRecorder()
+	serviceA.PostWeatherHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, weatherResponseFixture, rr.Body.String())
+	assert.NotEmpty(t, <-traceparents)
+
+	spans := recorder.Ended()
+	serviceASpan := findSpan(spans, "PostWeatherHandler")
+	serviceBSpan := findSpan(spans, "GetWeatherHandler")
+	locationSpan := findSpan(spans, "getLocationByCEP")
+	weatherSpan := findSpan(spans, "getWeatherByLocation")
+	if !assert.NotNil(t, serviceASpan) || !assert.NotNil(t, serviceBSpan) ||
+		!assert.NotNil(t, locationSpan) || !assert.NotNil(t, weatherSpan) {
+		return
+	}
+	traceID := serviceASpan.SpanContext().TraceID()
+	assert.Equal(t, traceID, serviceBSpan.SpanContext().TraceID())
+	assert.Equal(t, traceID, locationSpan.SpanContext().TraceID())
+	assert.Equal(t, traceID, weatherSpan.SpanContext().TraceID())
+	assert.Equal(t, serviceASpan.SpanContext().SpanID(), serviceBSpan.Parent().SpanID())
+	assert.Equal(t, serviceBSpan.SpanContext().SpanID(), locationSpan.Parent().SpanID())
+	assert.Equal(t, serviceBSpan.SpanContext().SpanID(), weatherSpan.Parent().SpanID())
+	if assert.Len(t, serviceBSpan.Links(), 1) {
+		link := serviceBSpan.Links()[0]
+		assert.Equal(t, traceID, link.SpanContext.TraceID())
+		assert.Equal(t, serviceASpan.SpanContext().SpanID(), link.SpanContext.SpanID())
+		assert.Contains(t, link.Attributes, linkTypeKey.String("downstream"))
+	}
+}
+
+func TestCorrelationIDReachesServiceB(t *testing.T) {
+	verifyNoLeaks(t)
+	recorder := newSpanRecorder(t)
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	traceparents := make(chan string, 1)
+	serviceB := newServiceB(t, viaCEP, weatherAPI, traceparents)
+	serviceA := middleware.ClientCorrelationIDMiddleware(
+		http.HandlerFunc(NewServiceAHandler(ServiceAConfig{ServiceBURL: serviceB.URL}).PostWeatherHandler))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/weather-service-a", strings.NewReader(`{"cep": "06233903"}`))
+	req.Header.Set(middleware.CorrelationIDHeader, "session 42")
+	rr := httptest.NewRecorder()
+	serviceA.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	<-traceparents
+	serviceBSpan := findSpan(recorder.Ended(), "GetWeatherHandler")
+	if !assert.NotNil(t, serviceBSpan) {
+		return
+	}
+	assert.Contains(t, serviceBSpan.Attributes(), correlationIDKey.String("session 42"))
+}
+
+func TestServiceAToServiceBOverGRPC(t *testing.T) {
+	verifyNoLeaks(t)
+	recorder := newSpanRecorder(t)
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	grpcServer := NewWeatherGRPCServer(NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+	}), GRPCServerConfig{})
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	serviceBClient, err := NewGRPCServiceBClient(GRPCServiceBClientConfig{Target: listener.Addr().String()})
+	if !assert.NoError(t, err) {
+		return
+	}
+	t.Cleanup(func() { serviceBClient.Close() })
+	serviceA := NewServiceAHandler(ServiceAConfig{ServiceBClient: serviceBClient})
+
+	tests := []struct {
+		name   string
+		cep    string
+		status int
+	}{
+		{name: "Valid CEP", cep: "06233903", status: http.StatusOK},
+		{name: "Unknown CEP", cep: "12345678", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/weather-service-a", strings.NewReader(`{"cep": "`+tt.cep+`"}`))
+			rr := httptest.NewRecorder()
+			serviceA.PostWeatherHandler(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+		})
+	}
+
+	// service A -> gRPC client span -> gRPC server span -> service B.
+	spans := recorder.Ended()
+	serviceASpan := findSpan(spans, "PostWeatherHandler")
+	rpcSpans := findSpans(spans, "weather.v1.WeatherService/GetWeather")
+	serviceBSpan := findSpan(spans, "GetWeatherGRPC")
+	if !assert.NotNil(t, serviceASpan) || !assert.Len(t, rpcSpans, 4) || !assert.NotNil(t, serviceBSpan) {
+		return
+	}
+	clientSpan, serverSpan := rpcSpans[0], rpcSpans[1]
+	if clientSpan.SpanKind() != trace.SpanKindClient {
+		clientSpan, serverSpan = serverSpan, clientSpan
+	}
+	traceID := serviceASpan.SpanContext().TraceID()
+	assert.Equal(t, traceID, serviceBSpan.SpanContext().TraceID())
+	assert.Equal(t, serviceASpan.SpanContext().SpanID(), clientSpan.Parent().SpanID())
+	assert.Equal(t, clientSpan.SpanContext().SpanID(), serverSpan.Parent().SpanID())
+	assert.Equal(t, serverSpan.SpanContext().SpanID(), serviceBSpan.Parent().SpanID())
+}
+
+func TestServiceAToServiceBWithInternalSecret(t *testing.T) {
+	verifyNoLeaks(t)
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	secret := []byte("secret")
+	router := NewRouter(RouterConfig{
+		ServiceA: NewServiceAHandler(ServiceAConfig{}),
+		ServiceB: NewServiceBHandler(ServiceBConfig{
+			CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+			WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+		}),
+		InternalSecret: secret,
+	})
+	serviceB := httptest.NewServer(router)
+	t.Cleanup(serviceB.Close)
+
+	tests := []struct {
+		name   string
+		secret []byte
+		status int
+	}{
+		{name: "Signed by service A", secret: secret, status: http.StatusOK},
+		{name: "Unsigned", status: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceA := NewServiceAHandler(ServiceAConfig{ServiceBURL: serviceB.URL, InternalSecret: tt.secret})
+			req := httptest.NewRequest(http.MethodPost, "/v1/weather-service-a", strings.NewReader(`{"cep": "06233903"}`))
+			rr := httptest.NewRecorder()
+			serviceA.PostWeatherHandler(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+		})
+	}
+
+	t.Run("Unsigned forecast", func(t *testing.T) {
+		resp, err := http.Get(serviceB.URL + "/v1/weather/06233903/forecast")
+		if !assert.NoError(t, err) {
+			return
+		}
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestServiceAToServiceBIsNotRateLimited(t *testing.T) {
+	verifyNoLeaks(t)
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	secret := []byte("secret")
+	// Service A calls service B on the same server, as in the shipped configuration.
+	server := httptest.NewUnstartedServer(nil)
+	server.Config.Handler = NewRouter(RouterConfig{
+		ServiceA: NewServiceAHandler(ServiceAConfig{ServiceBURL: "http://" + server.Listener.Addr().String(), InternalSecret: secret}),
+		ServiceB: NewServiceBHandler(ServiceBConfig{
+			CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+			WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+		}),
+		InternalSecret: secret,
+		RateLimitRPS:   1,
+		RateLimitBurst: 2,
+		// The frozen clock does not refill the bucket.
+		Clock: clock.NewFakeClock(time.Now()),
+	})
+	server.Start()
+	t.Cleanup(server.Close)
+
+	post := func() int {
+		resp, err := http.Post(server.URL+"/v1/weather-service-a", "application/json", strings.NewReader(`{"cep": "06233903"}`))
+		if !assert.NoError(t, err) {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Both requests of the burst reach service B, whose calls do not use up the tokens of the clients.
+	assert.Equal(t, http.StatusOK, post())
+	assert.Equal(t, http.StatusOK, post())
+	assert.Equal(t, http.StatusTooManyRequests, post())
+}
+
+func TestServiceAToServiceBOverGRPCWithInternalSecret(t *testing.T) {
+	verifyNoLeaks(t)
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	secret := []byte("secret")
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	grpcServer := NewWeatherGRPCServer(NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+	}), GRPCServerConfig{InternalSecret: secret})
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	tests := []struct {
+		name   string
+		secret []byte
+		code   codes.Code
+	}{
+		{name: "Signed by service A", secret: secret, code: codes.OK},
+		{name: "Unsigned", code: codes.Unauthenticated},
+		{name: "Wrong secret", secret: []byte("guess"), code: codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceBClient, err := NewGRPCServiceBClient(GRPCServiceBClientConfig{Target: listener.Addr().String(), InternalSecret: tt.secret})
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer serviceBClient.Close()
+
+			_, err = serviceBClient.GetWeather(context.Background(), "06233903")
+
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
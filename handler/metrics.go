@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"log"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// meterName scopes the instruments of the handlers.
+const meterName = "github.com/leoseiji/go-tracing/handler"
+
+// latencyBucketsMs are the histogram buckets of the upstream call latencies, in milliseconds.
+var latencyBucketsMs = []float64{50, 100, 250, 500, 1000, 2500, 5000}
+
+// meter returns the meter of the handlers from meterProvider, which defaults to the global one.
+func meter(meterProvider metric.MeterProvider) metric.Meter {
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+	return meterProvider.Meter(meterName)
+}
+
+// newLatencyHistogram creates a histogram of durations in milliseconds bucketed by latencyBucketsMs.
+func newLatencyHistogram(meterProvider metric.MeterProvider, name, description string) metric.Float64Histogram {
+	histogram, err := meter(meterProvider).Float64Histogram(name,
+		metric.WithUnit("ms"),
+		metric.WithDescription(description),
+		metric.WithExplicitBucketBoundaries(latencyBucketsMs...),
+	)
+	if err != nil {
+		log.Printf("error creating histogram %s. Err:%s", name, err.Error())
+		return noop.Float64Histogram{}
+	}
+	return histogram
+}
+
+// newCounter creates a counter of occurrences.
+func newCounter(meterProvider metric.MeterProvider, name, description string) metric.Int64Counter {
+	counter, err := meter(meterProvider).Int64Counter(name, metric.WithDescription(description))
+	if err != nil {
+		log.Printf("error creating counter %s. Err:%s", name, err.Error())
+		return noop.Int64Counter{}
+	}
+	return counter
+}
+
+// cepValidation counts the CEPs validated by a service, apart the valid and the invalid ones.
+type cepValidation struct {
+	service string
+	valid   metric.Int64Counter
+	invalid metric.Int64Counter
+}
+
+// newCEPValidation creates the counters of the CEPs validated by service, recorded with the service attribute.
+func newCEPValidation(meterProvider metric.MeterProvider, service string) cepValidation {
+	return cepValidation{
+		service: service,
+		valid:   newCounter(meterProvider, "cep_validation_valid_total", "CEPs validated as 8 digits, by service."),
+		invalid: newCounter(meterProvider, "cep_validation_invalid_total", "CEPs rejected as not 8 digits, by service."),
+	}
+}
+
+// isCepValid reports whether cep is valid, counting it as valid or invalid.
+func (v cepValidation) isCepValid(ctx context.Context, cep string) bool {
+	attrs := metric.WithAttributes(serviceKey.String(v.service))
+	if !isCepValid(cep) {
+		v.invalid.Add(ctx, 1, attrs)
+		return false
+	}
+	v.valid.Add(ctx, 1, attrs)
+	return true
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// collectMetrics returns the metrics recorded so far by reader, by name.
+func collectMetrics(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Metrics {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if !assert.NoError(t, reader.Collect(context.Background(), &rm)) {
+		return nil
+	}
+	metrics := make(map[string]metricdata.Metrics)
+	for _, scopeMetrics := range rm.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			metrics[m.Name] = m
+		}
+	}
+	return metrics
+}
+
+func TestUpstreamLookupMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	cepClient := NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL, MeterProvider: meterProvider})
+	weatherProvider := NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test", MeterProvider: meterProvider})
+	precomputed := NewPrecomputedWeather([]string{"06233903"}, DefaultPrecomputedRefreshInterval, cepClient, weatherProvider)
+	precomputed.Refresh(context.Background())
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       cepClient,
+		WeatherProvider: weatherProvider,
+		Precomputed:     precomputed,
+		MeterProvider:   meterProvider,
+	})
+	router := http.NewServeMux()
+	router.HandleFunc("GET /weather/{cep}", serviceB.GetWeatherHandler)
+
+	// The refresh looked up 06233903 once, the first request is served from it,
+	// the second one misses it and reaches ViaCEP only.
+	for _, path := range []string{"/weather/06233903", "/weather/12345678"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	metrics := collectMetrics(t, reader)
+	for name, count := range map[string]uint64{"cep_lookup_duration_ms": 2, "weather_lookup_duration_ms": 1} {
+		histogram, ok := metrics[name].Data.(metricdata.Histogram[float64])
+		if !assert.True(t, ok, name) || !assert.Len(t, histogram.DataPoints, 1, name) {
+			continue
+		}
+		assert.Equal(t, "ms", metrics[name].Unit, name)
+		assert.Equal(t, count, histogram.DataPoints[0].Count, name)
+		assert.Equal(t, latencyBucketsMs, histogram.DataPoints[0].Bounds, name)
+	}
+	for name, value := range map[string]int64{"cep_cache_hits_total": 1, "cep_cache_misses_total": 1} {
+		sum, ok := metrics[name].Data.(metricdata.Sum[int64])
+		if assert.True(t, ok, name) && assert.Len(t, sum.DataPoints, 1, name) {
+			assert.Equal(t, value, sum.DataPoints[0].Value, name)
+		}
+	}
+}
+
+func TestMetricsEndpointIncrementsOnRequest(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	exporter, err := otelprometheus.New(
+		otelprometheus.WithRegisterer(registry),
+		otelprometheus.WithoutScopeInfo(),
+		otelprometheus.WithoutTargetInfo(),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(exporter))
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+		MeterProvider:   meterProvider,
+	})
+	router := http.NewServeMux()
+	router.HandleFunc("GET /weather/{cep}", serviceB.GetWeatherHandler)
+	router.Handle("GET /metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	for _, path := range []string{"/weather/06233903", "/weather/12345678"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `requests_total{handler="GetWeatherHandler",status="200"} 1`+"\n")
+	assert.Contains(t, rr.Body.String(), `requests_total{handler="GetWeatherHandler",status="404"} 1`+"\n")
+}
+
+func TestCEPValidationMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+		MeterProvider:   meterProvider,
+	})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiPath("/weather-service-b/{cep}"), serviceB.GetWeatherHandler)
+	serviceBServer := httptest.NewServer(mux)
+	t.Cleanup(serviceBServer.Close)
+	// Service A forwards the valid CEPs only, so service B validates a single one.
+	serviceA := NewServiceAHandler(ServiceAConfig{ServiceBURL: serviceBServer.URL, MeterProvider: meterProvider})
+
+	for _, cep := range []string{"06233903", "0623390", "invalid"} {
+		req := httptest.NewRequest(http.MethodPost, "/weather-service-a", strings.NewReader(`{"cep": "`+cep+`"}`))
+		serviceA.PostWeatherHandler(httptest.NewRecorder(), req)
+	}
+
+	metrics := collectMetrics(t, reader)
+	tests := []struct {
+		name    string
+		service string
+		want    int64
+	}{
+		{name: "cep_validation_valid_total", service: "service-a", want: 1},
+		{name: "cep_validation_invalid_total", service: "service-a", want: 2},
+		{name: "cep_validation_valid_total", service: "service-b", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" "+tt.service, func(t *testing.T) {
+			sum, ok := metrics[tt.name].Data.(metricdata.Sum[int64])
+			if !assert.True(t, ok) {
+				return
+			}
+			var got int64
+			for _, dataPoint := range sum.DataPoints {
+				if service, _ := dataPoint.Attributes.Value(serviceKey); service.AsString() == tt.service {
+					got += dataPoint.Value
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
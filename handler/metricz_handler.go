@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/leoseiji/go-tracing/dto"
+	"github.com/leoseiji/go-tracing/middleware"
+)
+
+// NewMetriczHandler serves the latency percentiles computed by tracker.
+func NewMetriczHandler(tracker *middleware.P99Tracker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		latency := dto.LatencyResponse{
+			P99Ms: tracker.Percentile(99),
+			P95Ms: tracker.Percentile(95),
+			P50Ms: tracker.Percentile(50),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(latency)
+	}
+}
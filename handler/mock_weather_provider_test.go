@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"context"
+	"sync"
+
+	"github.com/leoseiji/go-tracing/dto"
+)
+
+// MockWeatherProvider is a WeatherProvider that returns fixed fixtures.
+// It is used by the tests in place of WeatherAPIProvider.
+type MockWeatherProvider struct {
+	Weather *dto.Weather
+	Err     error
+
+	mu        sync.Mutex
+	locations []string
+}
+
+func (m *MockWeatherProvider) GetWeather(_ context.Context, location string) (*dto.Weather, error) {
+	m.mu.Lock()
+	m.locations = append(m.locations, location)
+	m.mu.Unlock()
+
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return m.Weather, nil
+}
+
+// Locations returns the locations GetWeather was called with, in order.
+func (m *MockWeatherProvider) Locations() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.locations...)
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/leoseiji/go-tracing/dto"
+)
+
+// responseFormat is a media type the responses can be encoded in.
+type responseFormat struct {
+	mediaType   string
+	contentType string
+	encode      func(w io.Writer, v any) error
+}
+
+var (
+	jsonFormat = responseFormat{
+		mediaType:   "application/json",
+		contentType: "application/json",
+		encode:      func(w io.Writer, v any) error { return json.NewEncoder(w).Encode(v) },
+	}
+	xmlFormat = responseFormat{
+		mediaType:   "application/xml",
+		contentType: "application/xml; charset=utf-8",
+		encode: func(w io.Writer, v any) error {
+			if _, err := io.WriteString(w, xml.Header); err != nil {
+				return err
+			}
+			return xml.NewEncoder(w).Encode(v)
+		},
+	}
+	csvFormat = responseFormat{
+		mediaType:   "text/csv",
+		contentType: "text/csv; charset=utf-8",
+		encode: func(w io.Writer, v any) error {
+			records, ok := v.(csvRecorder)
+			if !ok {
+				return fmt.Errorf("%T can not be encoded in CSV", v)
+			}
+			return csv.NewWriter(w).WriteAll(records.CSVRecords())
+		},
+	}
+)
+
+// csvRecorder is implemented by the responses that can be encoded in CSV.
+type csvRecorder interface {
+	CSVRecords() [][]string
+}
+
+// responseFormats are the formats served, the first one being the default.
+var responseFormats = []responseFormat{jsonFormat, xmlFormat, csvFormat}
+
+// acceptableFormats lists the media types of responseFormats, as in the Allow header of 406 answers.
+var acceptableFormats = func() string {
+	mediaTypes := make([]string, 0, len(responseFormats))
+	for _, format := range responseFormats {
+		mediaTypes = append(mediaTypes, format.mediaType)
+	}
+	return strings.Join(mediaTypes, ", ")
+}()
+
+var ErrNotAcceptable = fmt.Errorf("acceptable formats: %s", acceptableFormats)
+
+// writeNotAcceptable answers 406 Not Acceptable, listing the formats served in the body and the Allow header.
+func writeNotAcceptable(w http.ResponseWriter) {
+	w.Header().Set("Allow", acceptableFormats)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotAcceptable)
+	json.NewEncoder(w).Encode(dto.ErrorResponse{Error: ErrNotAcceptable.Error()})
+}
+
+// negotiateFormat picks the format of the response preferred by the Accept header accept.
+// As in RFC 9110 section 12.5.1, the quality of a format is the one of the most specific media range
+// matching it, so that q=0 excludes a format accepted by a wildcard, such as in "application/json;q=0, */*".
+// Formats of equal quality are picked in the order of their media ranges, then in the order of responseFormats.
+// A missing header accepts any format, served in the default one.
+// It returns false when accept lists none of the formats served.
+func negotiateFormat(accept string) (responseFormat, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return responseFormats[0], true
+	}
+	mediaRanges := parseAccept(accept)
+	var best responseFormat
+	bestQ, bestIndex := 0.0, 0
+	for _, format := range responseFormats {
+		q, index := formatQuality(mediaRanges, format.mediaType)
+		if q > bestQ || q == bestQ && q > 0 && index < bestIndex {
+			best, bestQ, bestIndex = format, q, index
+		}
+	}
+	return best, bestQ > 0
+}
+
+// acceptedMediaRange is a media range of an Accept header with its quality.
+type acceptedMediaRange struct {
+	mediaRange string
+	q          float64
+}
+
+// parseAccept parses the media ranges of the Accept header accept, skipping the malformed ones.
+func parseAccept(accept string) []acceptedMediaRange {
+	var mediaRanges []acceptedMediaRange
+	for _, value := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(value)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		mediaRanges = append(mediaRanges, acceptedMediaRange{mediaRange: mediaRange, q: q})
+	}
+	return mediaRanges
+}
+
+// formatQuality returns the quality of mediaType given by the most specific of mediaRanges matching it,
+// the first one when several are as specific, and the index of that media range.
+// The quality is 0 when none matches.
+func formatQuality(mediaRanges []acceptedMediaRange, mediaType string) (q float64, index int) {
+	bestSpecificity := 0
+	for i, mediaRange := range mediaRanges {
+		if specificity := mediaRangeSpecificity(mediaRange.mediaRange, mediaType); specificity > bestSpecificity {
+			bestSpecificity, q, index = specificity, mediaRange.q, i
+		}
+	}
+	return q, index
+}
+
+// mediaRangeSpecificity ranks how specifically the media range mediaRange, such as */* or application/*,
+// includes mediaType: 3 for the media type itself, 2 for its type wildcard, 1 for */* and 0 when it does not.
+func mediaRangeSpecificity(mediaRange, mediaType string) int {
+	kind, _, _ := strings.Cut(mediaType, "/")
+	switch mediaRange {
+	case mediaType:
+		return 3
+	case kind + "/*":
+		return 2
+	case "*/*":
+		return 1
+	default:
+		return 0
+	}
+}
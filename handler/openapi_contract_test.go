@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/leoseiji/go-tracing/docs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPISpecMatchesHandlerResponses(t *testing.T) {
+	ctx := context.Background()
+	spec, err := openapi3.NewLoader().LoadFromData(docs.OpenAPIYAML)
+	if !assert.NoError(t, err) || !assert.NoError(t, spec.Validate(ctx)) {
+		return
+	}
+	// The requests are matched against the paths of the spec whatever the host of the test server.
+	spec.Servers = nil
+	specRouter, err := legacy.NewRouter(spec)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	weatherProvider := NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"})
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:        NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider:  weatherProvider,
+		ForecastProvider: weatherProvider,
+	})
+	// Service A calls service B on the same server, as when both run in one process.
+	var router http.Handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		router.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	router = NewRouter(RouterConfig{
+		ServiceA: NewServiceAHandler(ServiceAConfig{ServiceBURL: server.URL}),
+		ServiceB: serviceB,
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{name: "Weather of a CEP", method: http.MethodPost, path: "/v1/weather-service-a", body: `{"cep": "06233903"}`, status: http.StatusOK},
+		{name: "Weather of an invalid CEP", method: http.MethodPost, path: "/v1/weather-service-a", body: `{"cep": "0623390"}`, status: http.StatusUnprocessableEntity},
+		{name: "Weather of an unknown CEP", method: http.MethodPost, path: "/v1/weather-service-a", body: `{"cep": "12345678"}`, status: http.StatusNotFound},
+		{name: "Batch", method: http.MethodPost, path: "/v1/weather/batch", body: `{"ceps": ["06233903"]}`, status: http.StatusOK},
+		{name: "Batch with failures", method: http.MethodPost, path: "/v1/weather/batch", body: `{"ceps": ["06233903", "12345678", "0623390"]}`, status: http.StatusMultiStatus},
+		{name: "Service B", method: http.MethodGet, path: "/v1/weather-service-b/06233903", status: http.StatusOK},
+		{name: "Service B with an unknown CEP", method: http.MethodGet, path: "/v1/weather-service-b/12345678", status: http.StatusNotFound},
+		{name: "Forecast", method: http.MethodGet, path: "/v1/weather/06233903/forecast?days=2", status: http.StatusOK},
+		{name: "Forecast with invalid days", method: http.MethodGet, path: "/v1/weather/06233903/forecast?days=15", status: http.StatusBadRequest},
+		{name: "Health", method: http.MethodGet, path: "/health", status: http.StatusOK},
+		{name: "Version", method: http.MethodGet, path: "/version", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+			if !assert.NoError(t, err) {
+				return
+			}
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			route, pathParams, err := specRouter.FindRoute(req)
+			if !assert.NoError(t, err, "the endpoint is not documented") {
+				return
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			// The request is validated too, body included, so that the inputs of the test are valid ones per the spec.
+			// Its body was read by the server, it is read again from the test case.
+			validatedReq := req.Clone(ctx)
+			validatedReq.Body = io.NopCloser(strings.NewReader(tt.body))
+			requestInput := &openapi3filter.RequestValidationInput{
+				Request:    validatedReq,
+				PathParams: pathParams,
+				Route:      route,
+			}
+			if tt.status < http.StatusBadRequest {
+				assert.NoError(t, openapi3filter.ValidateRequest(ctx, requestInput))
+			}
+			responseInput := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: requestInput,
+				Status:                 resp.StatusCode,
+				Header:                 resp.Header,
+				Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+			}
+			responseInput.SetBodyBytes(body)
+			assert.NoError(t, openapi3filter.ValidateResponse(ctx, responseInput))
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/leoseiji/go-tracing/dto"
+	"go.opentelemetry.io/otel"
+)
+
+// DefaultPrecomputedRefreshInterval is how often the precomputed weather table is refreshed by default.
+const DefaultPrecomputedRefreshInterval = 15 * time.Minute
+
+// PopularCEPs are the most queried CEPs, the city centers of the largest Brazilian cities.
+var PopularCEPs = []string{
+	"01310100", // São Paulo
+	"20040020", // Rio de Janeiro
+	"70040010", // Brasília
+	"40020000", // Salvador
+	"60060000", // Fortaleza
+	"30130010", // Belo Horizonte
+	"69005010", // Manaus
+	"80010000", // Curitiba
+	"50010000", // Recife
+	"90010150", // Porto Alegre
+}
+
+// PrecomputedWeather is a lookup table holding the weather of a fixed set of CEPs,
+// refreshed in the background so that requests for them never wait on the upstreams.
+// A nil *PrecomputedWeather holds nothing.
+type PrecomputedWeather struct {
+	ceps            []string
+	interval        time.Duration
+	cepClient       CEPClient
+	weatherProvider WeatherProvider
+
+	mu        sync.RWMutex
+	responses map[string]*dto.CEPWeatherResponse
+}
+
+// NewPrecomputedWeather creates a table for ceps, refreshed every interval.
+// A non-positive interval falls back to DefaultPrecomputedRefreshInterval.
+func NewPrecomputedWeather(ceps []string, interval time.Duration, cepClient CEPClient, weatherProvider WeatherProvider) *PrecomputedWeather {
+	if interval <= 0 {
+		interval = DefaultPrecomputedRefreshInterval
+	}
+	return &PrecomputedWeather{
+		ceps:            ceps,
+		interval:        interval,
+		cepClient:       cepClient,
+		weatherProvider: weatherProvider,
+		responses:       make(map[string]*dto.CEPWeatherResponse),
+	}
+}
+
+// Start fills the table and keeps refreshing it in a background goroutine until ctx is done.
+func (p *PrecomputedWeather) Start(ctx context.Context) {
+	go func() {
+		p.Refresh(ctx)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.Refresh(ctx)
+			}
+		}
+	}()
+}
+
+// Refresh fetches the weather of every CEP of the table.
+// CEPs that fail keep their previous entry.
+func (p *PrecomputedWeather) Refresh(ctx context.Context) {
+	tracer := otel.Tracer("weather-service-b-precomputed")
+	ctx, span := tracer.Start(ctx, "refreshPrecomputedWeather")
+	defer span.End()
+
+	for _, cep := range p.ceps {
+		location, err := p.cepClient.GetLocation(ctx, cep)
+		if err != nil {
+			log.Printf("error while precomputing location of CEP %s. Err:%s", cep, err.Error())
+			continue
+		}
+		weather, err := p.weatherProvider.GetWeather(ctx, location.Location)
+		if err != nil {
+			log.Printf("error while precomputing weather of CEP %s. Err:%s", cep, err.Error())
+			continue
+		}
+
+		weatherResponse, err := dto.NewCEPWeatherResponse(location, weather)
+		if err != nil {
+			log.Printf("error while precomputing weather of CEP %s. Err:%s", cep, err.Error())
+			continue
+		}
+
+		p.mu.Lock()
+		p.responses[cep] = weatherResponse
+		p.mu.Unlock()
+	}
+}
+
+// Get returns the precomputed weather of cep, if any.
+func (p *PrecomputedWeather) Get(cep string) (*dto.CEPWeatherResponse, bool) {
+	if p == nil {
+		return nil, false
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	response, ok := p.responses[cep]
+	return response, ok
+}
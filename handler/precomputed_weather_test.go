@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWeatherHandlerServesPrecomputedWeather(t *testing.T) {
+	recorder := newSpanRecorder(t)
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	precomputed := NewPrecomputedWeather(
+		[]string{"06233903"},
+		DefaultPrecomputedRefreshInterval,
+		NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+	)
+	precomputed.Refresh(context.Background())
+
+	// Upstreams failing from now on prove the response comes from the table.
+	failingViaCEP, failingWeatherAPI := newUpstreams(t, http.StatusInternalServerError, http.StatusInternalServerError)
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: failingViaCEP.URL}),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: failingWeatherAPI.URL, APIKey: "test"}),
+		Precomputed:     precomputed,
+	})
+	router := http.NewServeMux()
+	router.HandleFunc("GET /weather/{cep}", serviceB.GetWeatherHandler)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/weather/06233903", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, weatherResponseFixture, rr.Body.String())
+	span := findSpan(recorder.Ended(), "GetWeatherHandler")
+	if assert.NotNil(t, span) && assert.Len(t, span.Events(), 1) {
+		assert.Equal(t, "cache.precomputed", span.Events()[0].Name)
+	}
+}
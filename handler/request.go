@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/leoseiji/go-tracing/middleware"
+)
+
+// decodeJSONBody decodes the JSON body of r into v.
+// When it can not, it answers the request and returns false:
+// 413 when the body exceeds the limit of middleware.BodyLimitMiddleware and 400 when it is malformed.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		middleware.WriteRequestBodyTooLarge(w)
+		return false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
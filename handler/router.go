@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/leoseiji/go-tracing/clock"
+	"github.com/leoseiji/go-tracing/dto"
+	"github.com/leoseiji/go-tracing/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+type RouterConfig struct {
+	ServiceA *ServiceAHandler
+	ServiceB *ServiceBHandler
+	// LatencyTracker is fed by the access log and reported by /metricz.
+	// Defaults to a tracker of middleware.DefaultLatencyWindow requests.
+	LatencyTracker *middleware.P99Tracker
+	// AccessLogSampleRate is the fraction of the requests logged by the access log: 0 disables it, 1 logs all of them.
+	AccessLogSampleRate float64
+	// AllowedOrigins are the origins of browser applications. Defaults to any origin.
+	AllowedOrigins []string
+	// MaxRequestBodyBytes bounds request bodies. Defaults to middleware.DefaultMaxRequestBodyBytes.
+	MaxRequestBodyBytes int64
+	// RequestTimeout bounds serving a request. Defaults to middleware.DefaultRequestTimeout.
+	RequestTimeout time.Duration
+	// GzipMinSize is the size from which responses are compressed. Defaults to middleware.DefaultGzipMinSize.
+	GzipMinSize int
+	// RateLimitRPS is the number of requests admitted per second, in bursts of up to RateLimitBurst.
+	// The rate is not limited when RateLimitRPS is not positive, unless it is set by PUT /admin/config.
+	// The requests signed with InternalSecret are not limited.
+	// RateLimitBurst defaults to 1.
+	RateLimitRPS   float64
+	RateLimitBurst int
+	// Clock measures the rate of the requests. Defaults to clock.Real.
+	Clock clock.Clock
+	// HealthChecks are run by /health?ready=true, keyed by dependency name.
+	HealthChecks map[string]HealthCheck
+	// Version is the build reported by /version.
+	Version dto.VersionResponse
+	// InternalSecret authenticates the requests to the weather and forecast routes of service B, see middleware.InternalAuthMiddleware.
+	// Service B is open to any caller when it is empty.
+	InternalSecret []byte
+	// AdminToken authenticates the operators calling the admin endpoints in the X-Admin-Token header.
+	// The admin endpoints are not served when it is empty.
+	AdminToken string
+	// CEPCache is flushed by POST /admin/cache/flush. Optional.
+	CEPCache *CachingCEPClient
+	// InternalSignatureSkew is the skew tolerated on the signatures. Defaults to middleware.DefaultSignatureSkew.
+	InternalSignatureSkew time.Duration
+}
+
+// NewRouter serves the routes of both services behind the standard middleware chain.
+func NewRouter(cfg RouterConfig) http.Handler {
+	if cfg.LatencyTracker == nil {
+		cfg.LatencyTracker = middleware.NewP99Tracker(middleware.DefaultLatencyWindow)
+	}
+	if len(cfg.AllowedOrigins) == 0 {
+		cfg.AllowedOrigins = []string{"*"}
+	}
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = middleware.DefaultRequestTimeout
+	}
+	if cfg.GzipMinSize <= 0 {
+		cfg.GzipMinSize = middleware.DefaultGzipMinSize
+	}
+	if cfg.MaxRequestBodyBytes <= 0 {
+		cfg.MaxRequestBodyBytes = middleware.DefaultMaxRequestBodyBytes
+	}
+	if cfg.InternalSignatureSkew <= 0 {
+		cfg.InternalSignatureSkew = middleware.DefaultSignatureSkew
+	}
+
+	rateLimiter := middleware.NewRateLimiter(cfg.RateLimitRPS, max(cfg.RateLimitBurst, 1), cfg.Clock)
+	if len(cfg.InternalSecret) > 0 {
+		// The calls of service A to service B are not counted against the rate of the clients.
+		rateLimiter.ExemptSigned(cfg.InternalSecret, cfg.InternalSignatureSkew)
+	}
+
+	mux := http.NewServeMux()
+
+	// handleFunc is a replacement for mux.HandleFunc
+	// which enriches the handler's HTTP instrumentation with the pattern as the http.route.
+	handleFunc := func(pattern string, handlerFunc func(http.ResponseWriter, *http.Request)) {
+		// Configure the "http.route" for the HTTP instrumentation.
+		handler := otelhttp.WithRouteTag(pattern, http.HandlerFunc(handlerFunc))
+		mux.Handle(pattern, handler)
+	}
+
+	handleFunc(apiPath("/weather-service-a"), cfg.ServiceA.PostWeatherHandler)
+	handleFunc("POST "+apiPath("/weather/batch"), cfg.ServiceA.PostWeatherBatchHandler)
+	// Service B is called by service A only, which signs its requests.
+	internalAuth := func(handlerFunc http.HandlerFunc) http.HandlerFunc {
+		if len(cfg.InternalSecret) == 0 {
+			return handlerFunc
+		}
+		return middleware.InternalAuthMiddleware(cfg.InternalSecret, cfg.InternalSignatureSkew)(handlerFunc).ServeHTTP
+	}
+	handleFunc(apiPath("/weather-service-b/{cep}"), internalAuth(cfg.ServiceB.GetWeatherHandler))
+	handleFunc("GET "+apiPath("/weather/{cep}/forecast"), internalAuth(cfg.ServiceB.GetWeatherForecastHandler))
+	// The unversioned paths are kept until the clients have moved to the versioned ones.
+	for _, pattern := range []string{"/weather-service-a", "POST /weather/batch", "/weather-service-b/{cep}", "GET /weather/{cep}/forecast"} {
+		handleFunc(pattern, redirectToAPIVersion)
+	}
+	handleFunc("GET /health", NewHealthHandler(cfg.HealthChecks))
+	handleFunc("GET /version", NewVersionHandler(cfg.Version))
+	handleFunc("GET /openapi.json", NewOpenAPIHandler())
+	handleFunc("GET /docs", DocsHandler)
+	handleFunc("GET /docs/init.js", DocsInitHandler)
+	if cfg.AdminToken != "" {
+		adminAuth := middleware.AuthMiddleware(cfg.AdminToken)
+		handleFunc("PUT /admin/config", adminAuth(NewConfigHandler(rateLimiter)).ServeHTTP)
+		if cfg.CEPCache != nil {
+			handleFunc("POST /admin/cache/flush", adminAuth(NewCacheFlushHandler(cfg.CEPCache)).ServeHTTP)
+		}
+	}
+	handleFunc("GET /metricz", NewMetriczHandler(cfg.LatencyTracker))
+	// OpenMetrics carries the exemplars linking data points to traces.
+	handleFunc("GET /metrics", promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}).ServeHTTP)
+
+	middlewares := []middleware.Middleware{
+		// Add HTTP instrumentation for the whole server.
+		otelHTTPMiddleware,
+		// The request ID is recorded on the span of the instrumentation.
+		middleware.RequestIDMiddleware,
+		// The access log runs inside it so that log records carry the request span.
+		middleware.AccessLogMiddleware(nil, cfg.LatencyTracker, cfg.AccessLogSampleRate),
+		// The security headers are set on every response, including the ones of the recovery and the timeout.
+		middleware.SecurityHeadersMiddleware,
+		// The answers of the recovery and the timeout are compressed too.
+		middleware.GzipMiddleware(cfg.GzipMinSize),
+		// Panics are recovered inside the access log so that it logs their 500.
+		middleware.RecoveryMiddleware(nil),
+		// Panics of the handlers timed out are raised again outside of it, to the recovery.
+		middleware.TimeoutMiddleware(cfg.RequestTimeout),
+		middleware.CORSMiddleware(cfg.AllowedOrigins),
+		rateLimiter.Middleware,
+		middleware.BodyLimitMiddleware(cfg.MaxRequestBodyBytes),
+		// The client correlation ID is added to the baggage extracted by the instrumentation.
+		middleware.ClientCorrelationIDMiddleware,
+	}
+	return middleware.Chain(mux, middlewares...)
+}
+
+func otelHTTPMiddleware(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "/")
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRouter(t *testing.T) {
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+	})
+	router := NewRouter(RouterConfig{
+		ServiceA:       NewServiceAHandler(ServiceAConfig{}),
+		ServiceB:       serviceB,
+		AllowedOrigins: []string{"https://app.example.com"},
+	})
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		origin      string
+		status      int
+		allowOrigin string
+		location    string
+	}{
+		{name: "Service B route", method: http.MethodGet, path: "/v1/weather-service-b/06233903", status: http.StatusOK},
+		{name: "Unversioned service B route", method: http.MethodGet, path: "/weather-service-b/06233903?units=metric", status: http.StatusMovedPermanently, location: "/v1/weather-service-b/06233903?units=metric"},
+		{name: "Unversioned batch route", method: http.MethodPost, path: "/weather/batch", status: http.StatusPermanentRedirect, location: "/v1/weather/batch"},
+		{name: "Version route", method: http.MethodGet, path: "/version", status: http.StatusOK},
+		{name: "OpenAPI route", method: http.MethodGet, path: "/openapi.json", status: http.StatusOK},
+		{name: "Docs route", method: http.MethodGet, path: "/docs", status: http.StatusOK},
+		{name: "Metrics route", method: http.MethodGet, path: "/metricz", status: http.StatusOK},
+		{name: "CORS preflight", method: http.MethodOptions, path: "/v1/weather/batch", origin: "https://app.example.com", status: http.StatusNoContent, allowOrigin: "https://app.example.com"},
+		{name: "Unknown route", method: http.MethodGet, path: "/unknown", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			assert.Equal(t, tt.allowOrigin, rr.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, tt.location, rr.Header().Get("Location"))
+			assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+		})
+	}
+}
+
+func TestShutdownDrainsInflightRequests(t *testing.T) {
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	started := make(chan struct{})
+	// slowViaCEP keeps the request in flight for 200 ms.
+	slowViaCEP := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		viaCEP.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(slowViaCEP.Close)
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: slowViaCEP.URL}),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+	})
+	srv := &http.Server{Handler: NewRouter(RouterConfig{ServiceA: NewServiceAHandler(ServiceAConfig{}), ServiceB: serviceB})}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	go srv.Serve(listener)
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	results := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/v1/weather-service-b/06233903")
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		results <- result{status: resp.StatusCode, body: string(body), err: err}
+	}()
+	<-started
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.NoError(t, srv.Shutdown(ctx))
+	// The request was 50 ms in, Shutdown waited for the remaining 150 ms.
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	select {
+	case res := <-results:
+		if assert.NoError(t, res.err) {
+			assert.Equal(t, http.StatusOK, res.status)
+			assert.JSONEq(t, weatherResponseFixture, res.body)
+		}
+	case <-ctx.Done():
+		t.Fatal("the in-flight request did not complete")
+	}
+}
@@ -2,19 +2,82 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
-	"io"
+	"errors"
 	"log"
 	"net/http"
+	"time"
 
+	"github.com/leoseiji/go-tracing/clock"
 	"github.com/leoseiji/go-tracing/dto"
+	"github.com/leoseiji/go-tracing/middleware"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 )
 
-var ErrInternalServerError = fmt.Errorf("internal server error")
+// ErrInternalServerError is answered for unexpected errors, the same as middleware.RecoveryMiddleware answers for panics.
+var ErrInternalServerError = middleware.ErrInternalServerError
 
-func PostWeatherHandler(w http.ResponseWriter, r *http.Request) {
+// DefaultServiceBURL is the service B used when ServiceAConfig.ServiceBURL is empty.
+const DefaultServiceBURL = "http://localhost:8080"
+
+// DefaultServiceBRetryDelay is the wait before retrying a service B call answered with 503.
+const DefaultServiceBRetryDelay = 500 * time.Millisecond
+
+type ServiceAConfig struct {
+	// ServiceBClient calls service B. Defaults to an HTTPServiceBClient
+	// configured with the fields below, which are ignored otherwise.
+	ServiceBClient ServiceBClient
+	// BatchMaxConcurrency bounds the concurrent service B calls of a batch lookup.
+	// Defaults to DefaultBatchMaxConcurrency.
+	BatchMaxConcurrency int
+
+	// ServiceBURL is the base URL of service B. Defaults to DefaultServiceBURL.
+	ServiceBURL string
+	// HTTPClient used for the requests to service B. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// ServiceBRetryOn503 retries a service B call once when it answers 503 Service Unavailable.
+	ServiceBRetryOn503 bool
+	// ServiceBRetryDelay is the wait before that retry. Defaults to DefaultServiceBRetryDelay.
+	ServiceBRetryDelay time.Duration
+	// Clock used to wait before retries. Defaults to clock.Real.
+	Clock clock.Clock
+	// InternalSecret signs the requests to service B. They are not signed when it is empty.
+	InternalSecret []byte
+	// MeterProvider counts the valid and invalid CEPs. Defaults to the global MeterProvider.
+	MeterProvider metric.MeterProvider
+}
+
+// ServiceAHandler validates CEPs and forwards them to service B.
+type ServiceAHandler struct {
+	serviceBClient      ServiceBClient
+	batchMaxConcurrency int
+	cepValidation       cepValidation
+}
+
+func NewServiceAHandler(cfg ServiceAConfig) *ServiceAHandler {
+	if cfg.ServiceBClient == nil {
+		cfg.ServiceBClient = NewHTTPServiceBClient(HTTPServiceBClientConfig{
+			ServiceBURL:    cfg.ServiceBURL,
+			HTTPClient:     cfg.HTTPClient,
+			RetryOn503:     cfg.ServiceBRetryOn503,
+			RetryDelay:     cfg.ServiceBRetryDelay,
+			Clock:          cfg.Clock,
+			InternalSecret: cfg.InternalSecret,
+		})
+	}
+	if cfg.BatchMaxConcurrency <= 0 {
+		cfg.BatchMaxConcurrency = DefaultBatchMaxConcurrency
+	}
+	return &ServiceAHandler{
+		serviceBClient:      cfg.ServiceBClient,
+		batchMaxConcurrency: cfg.BatchMaxConcurrency,
+		cepValidation:       newCEPValidation(cfg.MeterProvider, "service-a"),
+	}
+}
+
+func (h *ServiceAHandler) PostWeatherHandler(w http.ResponseWriter, r *http.Request) {
 	carrier := propagation.HeaderCarrier(r.Header)
 	ctx := r.Context()
 	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
@@ -24,59 +87,38 @@ func PostWeatherHandler(w http.ResponseWriter, r *http.Request) {
 	defer span.End()
 
 	var weatherCepRequest dto.WeatherCepRequest
-	if err := json.NewDecoder(r.Body).Decode(&weatherCepRequest); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !decodeJSONBody(w, r, &weatherCepRequest) {
 		return
 	}
 
-	if !isCepValid(weatherCepRequest.Cep) {
-		fmt.Printf("CEP %s is invalid", weatherCepRequest.Cep)
+	if !h.cepValidation.isCepValid(ctx, weatherCepRequest.Cep) {
 		http.Error(w, ErrCEPInvalid.Error(), http.StatusUnprocessableEntity)
 		return
 	}
 
-	url := fmt.Sprintf("http://localhost:8080/weather-service-b/%s", weatherCepRequest.Cep)
-	cepWeatherReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		log.Printf("error while creating request: %s", err)
-		http.Error(w, ErrInternalServerError.Error(), http.StatusInternalServerError)
+	weatherResponse, err := h.serviceBClient.GetWeather(ctx, weatherCepRequest.Cep)
+	if errors.Is(err, ErrCEPNotFound) {
+		http.Error(w, ErrCEPNotFound.Error(), http.StatusNotFound)
 		return
 	}
-	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(cepWeatherReq.Header))
-	resp, err := http.DefaultClient.Do(cepWeatherReq)
-	if err != nil {
-		log.Printf("error while making request: %s", err)
-		http.Error(w, ErrInternalServerError.Error(), http.StatusInternalServerError)
+	if errors.Is(err, ErrRequestCanceled) {
+		log.Printf("request canceled by the client: %s", err)
 		return
 	}
-	defer resp.Body.Close()
-
-	switch resp.StatusCode {
-	case http.StatusOK:
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			http.Error(w, ErrInternalServerError.Error(), http.StatusInternalServerError)
-			return
-		}
-		var location *dto.CEPWeatherResponse
-		if err = json.Unmarshal(body, &location); err != nil {
-			log.Printf("error while unmarshaling response: %s", err)
-			http.Error(w, ErrInternalServerError.Error(), http.StatusInternalServerError)
-			return
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		var statusErr *UnexpectedStatusError
+		if errors.As(err, &statusErr) {
+			span.SetAttributes(serviceBStatusCodeKey.Int(statusErr.StatusCode))
+			log.Printf("unexpected answer of service B: status %d, body %q", statusErr.StatusCode, statusErr.Body)
+		} else {
+			log.Printf("error while making request: %s", err)
 		}
-		w.WriteHeader(http.StatusOK)
-		w.Write(body)
-		return
-
-	case http.StatusNotFound:
-		log.Printf("error while making request: %s", err)
-		http.Error(w, ErrCEPNotFound.Error(), http.StatusNotFound)
-		return
-
-	default:
-		log.Printf("unexpected error: %s", err)
 		http.Error(w, ErrInternalServerError.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(weatherResponse)
 }
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/leoseiji/go-tracing/clock"
+	"github.com/leoseiji/go-tracing/middleware"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestServiceARetriesOnServiceB503(t *testing.T) {
+	var calls atomic.Int64
+	serviceB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(weatherResponseFixture))
+	}))
+	defer serviceB.Close()
+
+	fakeClock := clock.NewFakeClock(time.Now())
+	serviceA := NewServiceAHandler(ServiceAConfig{
+		ServiceBURL:        serviceB.URL,
+		ServiceBRetryOn503: true,
+		Clock:              fakeClock,
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/weather-service-a", strings.NewReader(`{"cep": "06233903"}`))
+	rr := httptest.NewRecorder()
+	serviceA.PostWeatherHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, weatherResponseFixture, rr.Body.String())
+	assert.Equal(t, int64(2), calls.Load())
+	if waits := fakeClock.Waits(); assert.Len(t, waits, 1) {
+		assert.InDelta(t, 500*time.Millisecond, waits[0], float64(10*time.Millisecond))
+	}
+}
+
+func TestPostWeatherHandlerBodyTooLarge(t *testing.T) {
+	// Service B is never called, the body is rejected while decoding it.
+	serviceA := NewServiceAHandler(ServiceAConfig{})
+	handler := middleware.BodyLimitMiddleware(8)(http.HandlerFunc(serviceA.PostWeatherHandler))
+
+	req := httptest.NewRequest(http.MethodPost, "/weather-service-a", strings.NewReader(`{"cep": "06233903"}`))
+	req.ContentLength = -1
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	assert.JSONEq(t, `{"error": "request body too large"}`, rr.Body.String())
+}
+
+func TestPostWeatherHandlerRecordsUnexpectedServiceBStatus(t *testing.T) {
+	recorder := newSpanRecorder(t)
+	serviceB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer serviceB.Close()
+	serviceA := NewServiceAHandler(ServiceAConfig{ServiceBURL: serviceB.URL})
+
+	req := httptest.NewRequest(http.MethodPost, "/weather-service-a", strings.NewReader(`{"cep": "06233903"}`))
+	rr := httptest.NewRecorder()
+	serviceA.PostWeatherHandler(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	span := findSpan(recorder.Ended(), "PostWeatherHandler")
+	if assert.NotNil(t, span) {
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Contains(t, span.Attributes(), serviceBStatusCodeKey.Int(http.StatusUnprocessableEntity))
+	}
+}
+
+// BenchmarkPostWeatherHandler includes the call to service B and its calls to the ViaCEP and WeatherAPI stubs.
+func BenchmarkPostWeatherHandler(b *testing.B) {
+	viaCEP, weatherAPI := newUpstreams(b, http.StatusOK, http.StatusOK)
+	serviceBHandler := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+	})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiPath("/weather-service-b/{cep}"), serviceBHandler.GetWeatherHandler)
+	serviceB := httptest.NewServer(mux)
+	b.Cleanup(serviceB.Close)
+	serviceA := NewServiceAHandler(ServiceAConfig{ServiceBURL: serviceB.URL})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rr := httptest.NewRecorder()
+		serviceA.PostWeatherHandler(rr, httptest.NewRequest(http.MethodPost, "/v1/weather-service-a", strings.NewReader(`{"cep": "06233903"}`)))
+		if rr.Code != http.StatusOK {
+			b.Fatalf("unexpected status code: %d", rr.Code)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/leoseiji/go-tracing/clock"
+	"github.com/leoseiji/go-tracing/dto"
+	"github.com/leoseiji/go-tracing/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"golang.org/x/net/http2"
+)
+
+// ServiceBClient looks up the weather of a CEP on service B.
+// It returns ErrCEPNotFound when service B can not find the CEP.
+type ServiceBClient interface {
+	GetWeather(ctx context.Context, cep string) (*dto.CEPWeatherResponse, error)
+}
+
+type HTTPServiceBClientConfig struct {
+	// ServiceBURL is the base URL of service B. Defaults to DefaultServiceBURL.
+	ServiceBURL string
+	// HTTPClient used for the requests to service B. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// RetryOn503 retries a call once when service B answers 503 Service Unavailable.
+	RetryOn503 bool
+	// RetryDelay is the wait before that retry. Defaults to DefaultServiceBRetryDelay.
+	RetryDelay time.Duration
+	// Clock used to wait before retries and to sign the requests. Defaults to clock.Real.
+	Clock clock.Clock
+	// InternalSecret signs the requests for the internal authentication of service B.
+	// The requests are not signed when it is empty.
+	InternalSecret []byte
+}
+
+// NewServiceBHTTPClient creates an HTTP client with its own transport for service B, secured by tlsConfig, which may be nil.
+// HTTP/2 is enabled unless disableHTTP2 is set, so the concurrent calls of a batch lookup share connections.
+// HTTP/2 is only negotiated with https service B URLs, such as a TLS terminating proxy in front of service B:
+// this server only serves plain HTTP/1.1, so the default http://localhost:8080 keeps using HTTP/1.1.
+func NewServiceBHTTPClient(tlsConfig *tls.Config, disableHTTP2 bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig.Clone()
+	}
+	if disableHTTP2 {
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		return &http.Client{Transport: transport}
+	}
+	if err := http2.ConfigureTransport(transport); err != nil {
+		log.Printf("error configuring HTTP/2 for service B, falling back to HTTP/1.1. Err:%s", err.Error())
+	}
+	return &http.Client{Transport: transport}
+}
+
+// maxErrorBodyBytes bounds the body of an unexpected answer of service B kept in UnexpectedStatusError.
+const maxErrorBodyBytes = 512
+
+// UnexpectedStatusError is returned when service B answers with a status other than 200 and 404.
+type UnexpectedStatusError struct {
+	StatusCode int
+	// Body is the start of the body of the answer, up to maxErrorBodyBytes.
+	Body string
+}
+
+func (e *UnexpectedStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// HTTPServiceBClient is a ServiceBClient calling the HTTP API of service B.
+type HTTPServiceBClient struct {
+	serviceBURL string
+	httpClient  *http.Client
+	retryOn503  bool
+	retryDelay  time.Duration
+	clock       clock.Clock
+	secret      []byte
+}
+
+func NewHTTPServiceBClient(cfg HTTPServiceBClientConfig) *HTTPServiceBClient {
+	if cfg.ServiceBURL == "" {
+		cfg.ServiceBURL = DefaultServiceBURL
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = DefaultServiceBRetryDelay
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real{}
+	}
+	return &HTTPServiceBClient{
+		serviceBURL: cfg.ServiceBURL,
+		httpClient:  cfg.HTTPClient,
+		retryOn503:  cfg.RetryOn503,
+		retryDelay:  cfg.RetryDelay,
+		clock:       cfg.Clock,
+		secret:      cfg.InternalSecret,
+	}
+}
+
+func (c *HTTPServiceBClient) GetWeather(ctx context.Context, cep string) (*dto.CEPWeatherResponse, error) {
+	resp, err := c.get(ctx, cep)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		var weatherResponse *dto.CEPWeatherResponse
+		if err = json.Unmarshal(body, &weatherResponse); err != nil {
+			log.Printf("error while unmarshaling response: %s", err)
+			return nil, err
+		}
+		return weatherResponse, nil
+
+	case http.StatusNotFound:
+		return nil, ErrCEPNotFound
+
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return nil, &UnexpectedStatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+}
+
+// get calls service B for cep, propagating the trace context of ctx and signing the request when a secret is set.
+// When enabled, a 503 answer is retried once after the retry delay.
+func (c *HTTPServiceBClient) get(ctx context.Context, cep string) (*http.Response, error) {
+	url := fmt.Sprintf("%s%s/%s", c.serviceBURL, apiPath("/weather-service-b"), cep)
+
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			log.Printf("error while creating request: %s", err)
+			return nil, err
+		}
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+		if len(c.secret) > 0 {
+			// Every attempt is signed anew, so that the retry is not rejected as expired.
+			middleware.SignRequest(req, c.secret, c.clock.Now())
+		}
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, canceledError(ctx, err)
+		}
+		if resp.StatusCode != http.StatusServiceUnavailable || !c.retryOn503 || attempt > 1 {
+			return resp, nil
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		log.Printf("service B unavailable, retrying in %s", c.retryDelay)
+		select {
+		case <-ctx.Done():
+			return nil, canceledError(ctx, ctx.Err())
+		case <-c.clock.After(c.retryDelay):
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceBHTTPClientNegotiatesHTTP2(t *testing.T) {
+	tests := []struct {
+		name         string
+		disableHTTP2 bool
+		protoMajor   int
+	}{
+		{name: "HTTP/2", protoMajor: 2},
+		{name: "HTTP/2 disabled", disableHTTP2: true, protoMajor: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protoMajors := make(chan int, 1)
+			serviceB := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				protoMajors <- r.ProtoMajor
+				w.Write([]byte(weatherResponseFixture))
+			}))
+			serviceB.EnableHTTP2 = true
+			serviceB.StartTLS()
+			t.Cleanup(serviceB.Close)
+			tlsConfig := &tls.Config{RootCAs: serviceB.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs}
+			client := NewHTTPServiceBClient(HTTPServiceBClientConfig{
+				ServiceBURL: serviceB.URL,
+				HTTPClient:  NewServiceBHTTPClient(tlsConfig, tt.disableHTTP2),
+			})
+
+			weatherResponse, err := client.GetWeather(context.Background(), "06233903")
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, "Osasco", weatherResponse.Location)
+			}
+			assert.Equal(t, tt.protoMajor, <-protoMajors)
+		})
+	}
+}
+
+func TestServiceBHTTPClientProtocol(t *testing.T) {
+	tests := []struct {
+		name         string
+		tls          bool
+		disableHTTP2 bool
+		protoMajor   int
+	}{
+		{name: "HTTPS", tls: true, protoMajor: 2},
+		{name: "HTTPS with HTTP/2 disabled", tls: true, disableHTTP2: true, protoMajor: 1},
+		{name: "Plain HTTP", protoMajor: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceB := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			serviceB.EnableHTTP2 = true
+			var tlsConfig *tls.Config
+			if tt.tls {
+				serviceB.StartTLS()
+				tlsConfig = &tls.Config{RootCAs: serviceB.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs}
+			} else {
+				serviceB.Start()
+			}
+			t.Cleanup(serviceB.Close)
+			httpClient := NewServiceBHTTPClient(tlsConfig, tt.disableHTTP2)
+
+			resp, err := httpClient.Get(serviceB.URL)
+
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+				assert.Equal(t, tt.protoMajor, resp.ProtoMajor)
+			}
+		})
+	}
+}
+
+func TestServiceBHTTPClientTruncatesUnexpectedBody(t *testing.T) {
+	serviceB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte("invalid zipcode" + strings.Repeat(".", 1024)))
+	}))
+	t.Cleanup(serviceB.Close)
+	client := NewHTTPServiceBClient(HTTPServiceBClientConfig{ServiceBURL: serviceB.URL})
+
+	_, err := client.GetWeather(context.Background(), "06233903")
+
+	var statusErr *UnexpectedStatusError
+	if assert.True(t, errors.As(err, &statusErr)) {
+		assert.Equal(t, http.StatusUnprocessableEntity, statusErr.StatusCode)
+		assert.Equal(t, "invalid zipcode"+strings.Repeat(".", maxErrorBodyBytes-len("invalid zipcode")), statusErr.Body)
+	}
+}
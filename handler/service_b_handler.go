@@ -1,165 +1,231 @@
 package handler
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"crypto/md5"
 	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
-	"net/url"
-	"regexp"
 	"strings"
+	"time"
 
 	"github.com/leoseiji/go-tracing/dto"
+	"github.com/leoseiji/go-tracing/middleware"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var ErrCEPNotFound = fmt.Errorf("can not find zipcode")
 var ErrCEPInvalid = fmt.Errorf("invalid zipcode")
+var ErrServiceUnavailable = fmt.Errorf("service unavailable")
+
+type ServiceBConfig struct {
+	CEPClient       CEPClient
+	WeatherProvider WeatherProvider
+	// ForecastProvider serves the forecasts. Defaults to WeatherProvider when it is a ForecastProvider,
+	// otherwise the forecasts are answered ErrServiceUnavailable.
+	ForecastProvider ForecastProvider
+	// SlowQueryLog logs slow lookups. Optional.
+	SlowQueryLog *SlowQueryLog
+	// Precomputed serves popular CEPs without calling the upstreams. Optional.
+	Precomputed *PrecomputedWeather
+	// MeterProvider counts the requests, the valid and invalid CEPs and the Precomputed hits and misses.
+	// Defaults to the global MeterProvider.
+	MeterProvider metric.MeterProvider
+}
+
+// ServiceBHandler serves the weather lookups of service B.
+type ServiceBHandler struct {
+	cepClient        CEPClient
+	weatherProvider  WeatherProvider
+	forecastProvider ForecastProvider
+	slowQueryLog     *SlowQueryLog
+	precomputed      *PrecomputedWeather
+	requests         metric.Int64Counter
+	cacheHits        metric.Int64Counter
+	cacheMisses      metric.Int64Counter
+	cepValidation    cepValidation
+}
+
+func NewServiceBHandler(cfg ServiceBConfig) *ServiceBHandler {
+	if cfg.ForecastProvider == nil {
+		cfg.ForecastProvider, _ = cfg.WeatherProvider.(ForecastProvider)
+	}
+	return &ServiceBHandler{
+		cepClient:        cfg.CEPClient,
+		weatherProvider:  cfg.WeatherProvider,
+		forecastProvider: cfg.ForecastProvider,
+		slowQueryLog:     cfg.SlowQueryLog,
+		precomputed:      cfg.Precomputed,
+		requests:         newCounter(cfg.MeterProvider, "requests_total", "Requests served, by handler and status code."),
+		cacheHits:        newCounter(cfg.MeterProvider, "cep_cache_hits_total", "Lookups served from the precomputed weather of popular CEPs."),
+		cacheMisses:      newCounter(cfg.MeterProvider, "cep_cache_misses_total", "Lookups of CEPs missing from the precomputed weather."),
+		cepValidation:    newCEPValidation(cfg.MeterProvider, "service-b"),
+	}
+}
 
-func GetWeatherHandler(w http.ResponseWriter, r *http.Request) {
+// downstreamLinks links the span of the caller propagated on ctx, service A's one, to the span started with them.
+// The span is already its child, the link shows the relationship on trace UIs not following parents across services.
+func downstreamLinks(ctx context.Context) []trace.SpanStartOption {
+	caller := trace.SpanContextFromContext(ctx)
+	if !caller.IsValid() || !caller.IsRemote() {
+		return nil
+	}
+	return []trace.SpanStartOption{trace.WithLinks(trace.Link{
+		SpanContext: caller,
+		Attributes:  []attribute.KeyValue{linkTypeKey.String("downstream")},
+	})}
+}
+
+func (h *ServiceBHandler) GetWeatherHandler(w http.ResponseWriter, r *http.Request) {
 	carrier := propagation.HeaderCarrier(r.Header)
 	ctx := r.Context()
 	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
 	tracer := otel.Tracer("weather-service-b")
-	_, span := tracer.Start(ctx, "GetWeatherHandler")
+	ctx, span := tracer.Start(ctx, "GetWeatherHandler", downstreamLinks(ctx)...)
 	defer span.End()
 
-	cep := r.PathValue("cep")
+	status := http.StatusOK
+	defer func() {
+		h.requests.Add(ctx, 1, metric.WithAttributes(handlerKey.String("GetWeatherHandler"), statusKey.Int(status)))
+	}()
 
-	if !isCepValid(cep) {
-		fmt.Printf("CEP %s is invalid", cep)
-		http.Error(w, ErrCEPInvalid.Error(), http.StatusUnprocessableEntity)
+	// The response is encoded in the format negotiated with the Accept header: JSON, XML or CSV.
+	format, ok := negotiateFormat(r.Header.Get("Accept"))
+	if !ok {
+		status = http.StatusNotAcceptable
+		writeNotAcceptable(w)
 		return
 	}
 
-	location, err := getLocationByCEP(ctx, cep)
+	weatherResponse, err := h.GetWeather(ctx, r.PathValue("cep"))
+	if errors.Is(err, ErrCEPInvalid) {
+		status = http.StatusUnprocessableEntity
+		http.Error(w, err.Error(), status)
+		return
+	}
 	if errors.Is(err, ErrCEPNotFound) {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		status = http.StatusNotFound
+		http.Error(w, err.Error(), status)
 		return
 	}
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if errors.Is(err, ErrRequestCanceled) {
+		// The client left, it is not answered.
+		log.Printf("request canceled by the client: %s", err)
+		status = statusClientClosedRequest
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		// An upstream timed out.
+		span.SetStatus(codes.Error, err.Error())
+		status = http.StatusServiceUnavailable
+		http.Error(w, ErrServiceUnavailable.Error(), status)
 		return
 	}
-
-	weather, err := getWeatherByLocation(ctx, location.Location)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		// The error is kept on the span, the client is only told the lookup failed.
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		status = http.StatusInternalServerError
+		http.Error(w, ErrInternalServerError.Error(), status)
 		return
 	}
 
-	weatherResponse := dto.NewCEPWeatherResponse(location, weather)
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(weatherResponse)
-}
-
-func isCepValid(cep string) bool {
-	if cep == "" {
-		return false
-	}
-	if len(cep) != 8 {
-		return false
+	var body bytes.Buffer
+	if err := format.encode(&body, weatherResponse); err != nil {
+		log.Printf("error encoding the weather response in %s. Err:%s", format.mediaType, err.Error())
+		span.SetStatus(codes.Error, err.Error())
+		status = http.StatusInternalServerError
+		http.Error(w, ErrInternalServerError.Error(), status)
+		return
 	}
-	if !regexp.MustCompile(`^[0-9]*$`).MatchString(cep) {
-		return false
+	// Caches and CDNs revalidate the response with its ETag.
+	etag := fmt.Sprintf(`"%x"`, md5.Sum(body.Bytes()))
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Add("Vary", "Accept")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		status = http.StatusNotModified
+		w.WriteHeader(status)
+		return
 	}
-	fmt.Printf("CEP %s is valid", cep)
-	return true
+	w.Header().Set("Content-Type", format.contentType)
+	w.Write(body.Bytes())
 }
 
-func getLocationByCEP(ctx context.Context, cep string) (*dto.Location, error) {
-	tracer := otel.Tracer("weather-service-b-get-location-by-cep")
-	_, span := tracer.Start(ctx, "getLocationByCEP")
-	defer span.End()
-
-	url := fmt.Sprintf("http://viacep.com.br/ws/%s/json/", cep)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		log.Printf("error creating ViaCEP request. Err:%s", err.Error())
-		return nil, err
+// etagMatches reports whether the If-None-Match header ifNoneMatch lists etag.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
 	}
+	return false
+}
 
-	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Printf("error executing ViaCEP request. Err:%s", err.Error())
-		return nil, err
+// GetWeather looks up the weather of the city of cep.
+// It is the business logic shared by the HTTP and gRPC servers of service B,
+// and records its attributes and events on the span in ctx.
+// It returns ErrCEPInvalid or ErrCEPNotFound for CEPs that can not be served.
+func (h *ServiceBHandler) GetWeather(ctx context.Context, cep string) (*dto.CEPWeatherResponse, error) {
+	span := trace.SpanFromContext(ctx)
+	if correlationID := baggage.FromContext(ctx).Member(middleware.CorrelationIDBaggageKey).Value(); correlationID != "" {
+		span.SetAttributes(correlationIDKey.String(correlationID))
 	}
-	defer resp.Body.Close()
-
-	switch resp.StatusCode {
 
-	case http.StatusOK:
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			log.Printf("error while reading ViaCEP result. Err:%s", err.Error())
-			return nil, err
-		}
-
-		var location *dto.Location
-		if err = json.Unmarshal(body, &location); err != nil {
-			log.Printf("error while converting ViaCEP result. Err:%s", err.Error())
-			return nil, err
-		}
-		if location.CEP == "" {
-			return nil, ErrCEPNotFound
-		}
-		return location, nil
-
-	case http.StatusNotFound:
-		return nil, ErrCEPNotFound
-
-	default:
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if !h.cepValidation.isCepValid(ctx, cep) {
+		return nil, ErrCEPInvalid
 	}
+	span.SetAttributes(cepKey.String(cep), cepRegionKey.String(dto.CEP(cep).Region()))
 
-}
-
-func getWeatherByLocation(ctx context.Context, location string) (*dto.Weather, error) {
-	tracer := otel.Tracer("weather-service-b-get-weather-by-location")
-	_, span := tracer.Start(ctx, "getWeatherByLocation")
-	defer span.End()
+	if weatherResponse, ok := h.precomputed.Get(cep); ok {
+		h.cacheHits.Add(ctx, 1)
+		span.AddEvent("cache.precomputed")
+		return weatherResponse, nil
+	}
+	if h.precomputed != nil {
+		h.cacheMisses.Add(ctx, 1)
+	}
 
-	location = strings.Replace(location, " ", "%20", -1)
-	reqUrl := fmt.Sprintf("http://api.weatherapi.com/v1/current.json?key=e6c189ac26084b8a84213356241706&q=%s", url.PathEscape(location))
+	slowQuery := SlowQuery{CEP: cep}
+	start := time.Now()
+	defer func() {
+		slowQuery.TotalDuration = time.Since(start)
+		h.slowQueryLog.Record(ctx, slowQuery)
+	}()
 
-	req, err := http.NewRequest(http.MethodGet, reqUrl, nil)
-	req.Header.Set("Content-Type", "application/json")
+	location, err := h.cepClient.GetLocation(ctx, cep)
+	slowQuery.ViaCEPDuration = time.Since(start)
 	if err != nil {
-		log.Printf("error creating weatherAPI request. Err:%s", err.Error())
 		return nil, err
 	}
 
-	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
-	resp, err := http.DefaultClient.Do(req)
+	span.SetAttributes(locationNameKey.String(location.Location))
+	slowQuery.Location = location.Location
+
+	weatherStart := time.Now()
+	weather, err := h.weatherProvider.GetWeather(ctx, location.Location)
+	slowQuery.WeatherAPIDuration = time.Since(weatherStart)
 	if err != nil {
-		log.Printf("error executing weatherAPI request. Err:%s", err.Error())
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		log.Printf("error while getting weatherAPI result. Status: %s, Body: %s", resp.Status, string(body))
-
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
 
-	body, err := io.ReadAll(resp.Body)
+	weatherResponse, err := dto.NewCEPWeatherResponse(location, weather)
 	if err != nil {
-		log.Printf("error while reading weatherAPI result. Err:%s", err.Error())
+		span.RecordError(err)
 		return nil, err
 	}
+	return weatherResponse, nil
+}
 
-	var weather *dto.Weather
-	if err = json.Unmarshal(body, &weather); err != nil {
-		log.Printf("error while converting weatherAPI result. Err:%s", err.Error())
-		return nil, err
-	}
-	return weather, nil
+func isCepValid(cep string) bool {
+	return dto.CEP(cep).IsValid()
 }
@@ -1,56 +1,557 @@
 package handler
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/xml"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
-	"github.com/gorilla/mux"
+	"github.com/leoseiji/go-tracing/dto"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
+const (
+	weatherAPIFixture = `{"current": {
+		"last_updated": "2024-06-17 10:00",
+		"temp_c": 25.0,
+		"temp_f": 77.0,
+		"feelslike_c": 26.0,
+		"feelslike_f": 78.8,
+		"humidity": 65,
+		"wind_kph": 9.0,
+		"wind_mph": 5.6,
+		"condition": {"text": "Sunny"}
+	}}`
+	weatherResponseFixture = `{
+		"city": "Osasco",
+		"lat": -23.5328,
+		"lng": -46.7917,
+		"temp_C": 25,
+		"temp_F": 77,
+		"temp_K": 298.15,
+		"feelslike_C": 26,
+		"feelslike_F": 78.8,
+		"humidity": 65,
+		"wind_kph": 9,
+		"wind_mph": 5.6,
+		"condition": "Sunny"
+	}`
+)
+
+// newUpstreams starts stub ViaCEP and WeatherAPI servers.
+// The ViaCEP stub knows only the CEP 06233903, any other CEP is answered as not found.
+// The WeatherAPI stub forecasts as many days as requested, see forecastAPIFixture.
+func newUpstreams(t testing.TB, viaCEPStatus, weatherAPIStatus int) (viaCEP, weatherAPI *httptest.Server) {
+	t.Helper()
+
+	viaCEP = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if viaCEPStatus != http.StatusOK {
+			w.WriteHeader(viaCEPStatus)
+			return
+		}
+		if r.URL.Path != "/ws/06233903/json/" {
+			w.Write([]byte(`{"erro": true}`))
+			return
+		}
+		w.Write([]byte(`{"cep": "06233-903", "localidade": "Osasco", "latitude": "-23.5328", "longitude": "-46.7917"}`))
+	}))
+	t.Cleanup(viaCEP.Close)
+
+	weatherAPI = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if weatherAPIStatus != http.StatusOK {
+			w.WriteHeader(weatherAPIStatus)
+			return
+		}
+		if r.URL.Path == "/v1/forecast.json" {
+			w.Write([]byte(forecastAPIFixture(r.URL.Query().Get("days"))))
+			return
+		}
+		w.Write([]byte(weatherAPIFixture))
+	}))
+	t.Cleanup(weatherAPI.Close)
+
+	return viaCEP, weatherAPI
+}
+
+// newSpanRecorder installs a tracer provider recording every span, along with
+// the W3C trace context propagator, for the duration of the test.
+func newSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func findSpan(spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+	return nil
+}
+
+// findSpans returns the spans named name, in the order they ended.
+func findSpans(spans []sdktrace.ReadOnlySpan, name string) []sdktrace.ReadOnlySpan {
+	var found []sdktrace.ReadOnlySpan
+	for _, span := range spans {
+		if span.Name() == name {
+			found = append(found, span)
+		}
+	}
+	return found
+}
+
 func TestGetWeatherHandler(t *testing.T) {
 	type args struct {
-		path   string
-		status int
+		path             string
+		viaCEPStatus     int
+		weatherAPIStatus int
+	}
+	type want struct {
+		status     int
+		body       string
+		spanStatus codes.Code
 	}
 	tests := []struct {
 		name string
 		args args
+		want want
 	}{
 		{
 			name: "Valid CEP returns 200",
 			args: args{
-				path:   "/weather/06233903",
-				status: 200,
+				path:             "/weather/06233903",
+				viaCEPStatus:     http.StatusOK,
+				weatherAPIStatus: http.StatusOK,
+			},
+			want: want{
+				status:     http.StatusOK,
+				body:       weatherResponseFixture,
+				spanStatus: codes.Unset,
 			},
 		},
 		{
 			name: "Invalid CEP returns 422",
 			args: args{
-				path:   "/weather/invalid",
-				status: 422,
+				path:             "/weather/invalid",
+				viaCEPStatus:     http.StatusOK,
+				weatherAPIStatus: http.StatusOK,
+			},
+			want: want{
+				status:     http.StatusUnprocessableEntity,
+				body:       "invalid zipcode\n",
+				spanStatus: codes.Unset,
 			},
 		},
 		{
 			name: "Empty CEP returns 404",
 			args: args{
-				path:   "/weather/12345678",
-				status: 404,
+				path:             "/weather/12345678",
+				viaCEPStatus:     http.StatusOK,
+				weatherAPIStatus: http.StatusOK,
+			},
+			want: want{
+				status:     http.StatusNotFound,
+				body:       "can not find zipcode\n",
+				spanStatus: codes.Unset,
+			},
+		},
+		{
+			name: "ViaCEP error returns 500",
+			args: args{
+				path:             "/weather/06233903",
+				viaCEPStatus:     http.StatusInternalServerError,
+				weatherAPIStatus: http.StatusOK,
+			},
+			want: want{
+				status:     http.StatusInternalServerError,
+				body:       "internal server error\n",
+				spanStatus: codes.Error,
+			},
+		},
+		{
+			name: "WeatherAPI error returns 500",
+			args: args{
+				path:             "/weather/06233903",
+				viaCEPStatus:     http.StatusOK,
+				weatherAPIStatus: http.StatusInternalServerError,
+			},
+			want: want{
+				status:     http.StatusInternalServerError,
+				body:       "internal server error\n",
+				spanStatus: codes.Error,
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			router := mux.NewRouter()
-			router.HandleFunc("/weather/{cep}", GetWeatherHandler).Methods("GET")
+			recorder := newSpanRecorder(t)
+			viaCEP, weatherAPI := newUpstreams(t, tt.args.viaCEPStatus, tt.args.weatherAPIStatus)
+			serviceB := NewServiceBHandler(ServiceBConfig{
+				CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+				WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+			})
+			router := http.NewServeMux()
+			router.HandleFunc("GET /weather/{cep}", serviceB.GetWeatherHandler)
 
 			req, _ := http.NewRequest(http.MethodGet, tt.args.path, nil)
 			req.RemoteAddr = "0.0.0.1:8000"
 			rr := httptest.NewRecorder()
 			router.ServeHTTP(rr, req)
 
-			assert.Equal(t, tt.args.status, rr.Code)
+			assert.Equal(t, tt.want.status, rr.Code)
+			if tt.want.status == http.StatusOK {
+				assert.JSONEq(t, tt.want.body, rr.Body.String())
+			} else {
+				assert.Equal(t, tt.want.body, rr.Body.String())
+			}
+
+			span := findSpan(recorder.Ended(), "GetWeatherHandler")
+			if assert.NotNil(t, span) {
+				assert.Equal(t, tt.want.spanStatus, span.Status().Code)
+			}
+			if tt.want.status == http.StatusOK {
+				assert.Contains(t, span.Attributes(), cepKey.String("06233903"))
+				assert.Contains(t, span.Attributes(), cepRegionKey.String("Grande São Paulo"))
+				assert.Contains(t, span.Attributes(), locationNameKey.String("Osasco"))
+				weatherSpan := findSpan(recorder.Ended(), "getWeatherByLocation")
+				if assert.NotNil(t, weatherSpan) {
+					assert.Contains(t, weatherSpan.Attributes(), weatherTempCKey.Float64(25))
+				}
+			}
+		})
+	}
+}
+
+func TestGetWeatherHandlerWithViaCEPTimeout(t *testing.T) {
+	verifyNoLeaks(t)
+
+	viaCEP := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(viaCEP.Close)
+	httpClient := &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()}
+	t.Cleanup(httpClient.CloseIdleConnections)
+	weatherProvider := &MockWeatherProvider{}
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL, Timeout: 50 * time.Millisecond, HTTPClient: httpClient}),
+		WeatherProvider: weatherProvider,
+	})
+	router := http.NewServeMux()
+	router.HandleFunc("GET /weather/{cep}", serviceB.GetWeatherHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/weather/06233903", nil)
+	rr := httptest.NewRecorder()
+	assert.NotPanics(t, func() { router.ServeHTTP(rr, req) })
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "service unavailable\n", rr.Body.String())
+	assert.Empty(t, weatherProvider.Locations())
+}
+
+func TestGetWeatherHandlerIncompleteWeather(t *testing.T) {
+	recorder := newSpanRecorder(t)
+	viaCEP, _ := newUpstreams(t, http.StatusOK, http.StatusOK)
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: &MockWeatherProvider{Weather: &dto.Weather{}},
+	})
+	router := http.NewServeMux()
+	router.HandleFunc("GET /weather/{cep}", serviceB.GetWeatherHandler)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/weather/06233903", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	span := findSpan(recorder.Ended(), "GetWeatherHandler")
+	if assert.NotNil(t, span) {
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Contains(t, span.Status().Description, dto.ErrIncompleteWeatherResponse.Error())
+	}
+}
+
+func TestGetWeatherHandlerHidesTheWeatherAPIKey(t *testing.T) {
+	recorder := newSpanRecorder(t)
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	weatherAPI.Close()
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "secret"}),
+	})
+	router := http.NewServeMux()
+	router.HandleFunc("GET /weather/{cep}", serviceB.GetWeatherHandler)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/weather/06233903", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, "internal server error\n", rr.Body.String())
+	span := findSpan(recorder.Ended(), "GetWeatherHandler")
+	if assert.NotNil(t, span) {
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Contains(t, span.Status().Description, "/v1/current.json?q=Osasco")
+		assert.NotContains(t, span.Status().Description, "secret")
+		for _, event := range span.Events() {
+			for _, attr := range event.Attributes {
+				assert.NotContains(t, attr.Value.Emit(), "secret")
+			}
+		}
+	}
+}
+
+func TestGetWeatherHandlerETag(t *testing.T) {
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+	})
+	router := http.NewServeMux()
+	router.HandleFunc("GET /weather/{cep}", serviceB.GetWeatherHandler)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/weather/06233903", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "public, max-age=300", rr.Header().Get("Cache-Control"))
+	etag := rr.Header().Get("ETag")
+	if !assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag) {
+		return
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		status      int
+	}{
+		{name: "Matching ETag", ifNoneMatch: etag, status: http.StatusNotModified},
+		{name: "Matching weak ETag among others", ifNoneMatch: `"other", W/` + etag, status: http.StatusNotModified},
+		{name: "Stale ETag", ifNoneMatch: `"other"`, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/weather/06233903", nil)
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			assert.Equal(t, etag, rr.Header().Get("ETag"))
+			if tt.status == http.StatusNotModified {
+				assert.Empty(t, rr.Body.String())
+			} else {
+				assert.JSONEq(t, weatherResponseFixture, rr.Body.String())
+			}
 		})
 	}
 }
+
+func TestContentNegotiationReturnsXML(t *testing.T) {
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+	})
+	router := http.NewServeMux()
+	router.HandleFunc("GET /weather/{cep}", serviceB.GetWeatherHandler)
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+	}{
+		{name: "XML", accept: "application/xml", contentType: "application/xml; charset=utf-8"},
+		{name: "XML preferred", accept: "application/json;q=0.5, application/xml", contentType: "application/xml; charset=utf-8"},
+		{name: "JSON", accept: "application/json", contentType: "application/json"},
+		{name: "CSV", accept: "text/csv", contentType: "text/csv; charset=utf-8"},
+		{name: "Any format", accept: "*/*", contentType: "application/json"},
+		{name: "JSON excluded", accept: "application/json;q=0, */*", contentType: "application/xml; charset=utf-8"},
+		{name: "Specific range over wildcard", accept: "text/csv;q=0.8, text/*;q=0.1, */*;q=0.5", contentType: "text/csv; charset=utf-8"},
+		{name: "First of equal quality", accept: "text/csv, application/xml", contentType: "text/csv; charset=utf-8"},
+		{name: "No Accept header", contentType: "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/weather/06233903", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.contentType, rr.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", rr.Header().Get("Vary"))
+			switch tt.contentType {
+			case "application/json":
+				assert.JSONEq(t, weatherResponseFixture, rr.Body.String())
+				return
+			case "text/csv; charset=utf-8":
+				records, err := csv.NewReader(rr.Body).ReadAll()
+				if assert.NoError(t, err) && assert.Len(t, records, 2) {
+					assert.Equal(t, []string{"city", "Osasco"}, []string{records[0][0], records[1][0]})
+				}
+				return
+			}
+			var weatherResponse dto.CEPWeatherResponse
+			if assert.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &weatherResponse)) {
+				assert.Equal(t, "weather", weatherResponse.XMLName.Local)
+				assert.Equal(t, "Osasco", weatherResponse.Location)
+			}
+		})
+	}
+}
+
+func TestContentNegotiationReturns406(t *testing.T) {
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	serviceB := NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+	})
+	router := http.NewServeMux()
+	router.HandleFunc("GET /weather/{cep}", serviceB.GetWeatherHandler)
+
+	for _, accept := range []string{"text/html", "application/*;q=0, text/csv;q=0, text/html"} {
+		t.Run(accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/weather/06233903", nil)
+			req.Header.Set("Accept", accept)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+			assert.JSONEq(t, `{"error":"acceptable formats: application/json, application/xml, text/csv"}`, rr.Body.String())
+			assert.Equal(t, "application/json, application/xml, text/csv", rr.Header().Get("Allow"))
+		})
+	}
+}
+
+func TestGetWeatherHandlerWithClientCanceled(t *testing.T) {
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	// hanging answers once the client left.
+	started := make(chan struct{}, 1)
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-r.Context().Done()
+	}))
+	t.Cleanup(hanging.Close)
+
+	tests := []struct {
+		name          string
+		viaCEPURL     string
+		weatherAPIURL string
+	}{
+		{name: "Canceled during the ViaCEP call", viaCEPURL: hanging.URL, weatherAPIURL: weatherAPI.URL},
+		{name: "Canceled during the WeatherAPI call", viaCEPURL: viaCEP.URL, weatherAPIURL: hanging.URL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceB := NewServiceBHandler(ServiceBConfig{
+				CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: tt.viaCEPURL}),
+				WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: tt.weatherAPIURL, APIKey: "test"}),
+			})
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				<-started
+				cancel()
+			}()
+
+			_, err := serviceB.GetWeather(ctx, "06233903")
+			assert.ErrorIs(t, err, ErrRequestCanceled)
+			assert.ErrorIs(t, err, context.Canceled)
+
+			// The handler logs the cancellation without answering.
+			ctx, cancel = context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				<-started
+				cancel()
+			}()
+			req := httptest.NewRequest(http.MethodGet, "/weather-service-b/06233903", nil).WithContext(ctx)
+			req.SetPathValue("cep", "06233903")
+			rr := httptest.NewRecorder()
+			serviceB.GetWeatherHandler(rr, req)
+
+			assert.Empty(t, rr.Body.String())
+			assert.Empty(t, rr.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestIsCepValid(t *testing.T) {
+	tests := []struct {
+		name string
+		cep  string
+		want bool
+	}{
+		{name: "Empty string is invalid", cep: "", want: false},
+		{name: "7 digits is invalid", cep: "0623390", want: false},
+		{name: "9 digits is invalid", cep: "062339031", want: false},
+		{name: "8 characters with letters is invalid", cep: "0623abcd", want: false},
+		{name: "8 characters with hyphen is invalid", cep: "0623-903", want: false},
+		{name: "Valid 8-digit CEP", cep: "01310100", want: true},
+		{name: "CEP with leading zero is valid", cep: "06233903", want: true},
+		{name: "All zeros is structurally valid", cep: "00000000", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isCepValid(tt.cep))
+		})
+	}
+}
+
+func BenchmarkGetWeatherHandler_CacheHit(b *testing.B) {
+	viaCEP, weatherAPI := newUpstreams(b, http.StatusOK, http.StatusOK)
+	cepClient := NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL})
+	weatherProvider := NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"})
+	precomputed := NewPrecomputedWeather([]string{"06233903"}, DefaultPrecomputedRefreshInterval, cepClient, weatherProvider)
+	precomputed.Refresh(context.Background())
+	benchmarkGetWeatherHandler(b, NewServiceBHandler(ServiceBConfig{
+		CEPClient:       cepClient,
+		WeatherProvider: weatherProvider,
+		Precomputed:     precomputed,
+	}))
+}
+
+// BenchmarkGetWeatherHandler_CacheMiss includes the calls to the ViaCEP and WeatherAPI stubs.
+func BenchmarkGetWeatherHandler_CacheMiss(b *testing.B) {
+	viaCEP, weatherAPI := newUpstreams(b, http.StatusOK, http.StatusOK)
+	benchmarkGetWeatherHandler(b, NewServiceBHandler(ServiceBConfig{
+		CEPClient:       NewViaCEPClient(ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+	}))
+}
+
+// benchmarkGetWeatherHandler serves lookups of the same CEP with serviceB.
+func benchmarkGetWeatherHandler(b *testing.B, serviceB *ServiceBHandler) {
+	router := http.NewServeMux()
+	router.HandleFunc("GET /weather/{cep}", serviceB.GetWeatherHandler)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/weather/06233903", nil))
+		if rr.Code != http.StatusOK {
+			b.Fatalf("unexpected status code: %d", rr.Code)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultSlowQueryThreshold is used when no threshold is configured.
+const DefaultSlowQueryThreshold = time.Second
+
+// SlowQuery holds the timings of a single weather lookup.
+type SlowQuery struct {
+	CEP                string
+	Location           string
+	ViaCEPDuration     time.Duration
+	WeatherAPIDuration time.Duration
+	TotalDuration      time.Duration
+}
+
+// SlowQueryLog logs lookups slower than a threshold at Warn level,
+// much like a database slow query log.
+// A nil *SlowQueryLog logs nothing.
+type SlowQueryLog struct {
+	threshold time.Duration
+	logger    *slog.Logger
+}
+
+func NewSlowQueryLog(threshold time.Duration, logger *slog.Logger) *SlowQueryLog {
+	if threshold <= 0 {
+		threshold = DefaultSlowQueryThreshold
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlowQueryLog{threshold: threshold, logger: logger}
+}
+
+// Record logs q if its total duration exceeds the threshold.
+// The trace ID is taken from the span in ctx for correlation.
+func (l *SlowQueryLog) Record(ctx context.Context, q SlowQuery) {
+	if l == nil || q.TotalDuration < l.threshold {
+		return
+	}
+	l.logger.WarnContext(ctx, "slow weather lookup",
+		slog.String("cep", q.CEP),
+		slog.String("location", q.Location),
+		slog.Float64("viacep_duration_ms", durationInMilliseconds(q.ViaCEPDuration)),
+		slog.Float64("weatherapi_duration_ms", durationInMilliseconds(q.WeatherAPIDuration)),
+		slog.Float64("total_duration_ms", durationInMilliseconds(q.TotalDuration)),
+		slog.Float64("threshold_ms", durationInMilliseconds(l.threshold)),
+		slog.String("trace_id", trace.SpanContextFromContext(ctx).TraceID().String()),
+	)
+}
+
+func durationInMilliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/leoseiji/go-tracing/dnscache"
+	tracing "github.com/leoseiji/go-tracing/otel"
+)
+
+const (
+	// DefaultConnectTimeout bounds establishing a connection to an upstream when Timeouts.Connect is not set.
+	DefaultConnectTimeout = 3 * time.Second
+	// DefaultReadTimeout bounds waiting for the response of an upstream when Timeouts.Read is not set.
+	DefaultReadTimeout = 5 * time.Second
+)
+
+// ErrRequestCanceled is returned by the upstream calls canceled with the request they serve,
+// usually because its client closed the connection. There is nobody left to answer, so it is only logged.
+var ErrRequestCanceled = fmt.Errorf("request canceled")
+
+// statusClientClosedRequest is the nonstandard status recorded for the requests whose client left before the answer.
+const statusClientClosedRequest = 499
+
+// userAgentURL points the operators of the upstreams to the service in its User-Agent.
+const userAgentURL = "https://github.com/leoseiji/go-tracing"
+
+// Timeouts bound the phases of the requests to an upstream separately,
+// so that an upstream that is down can be told apart from a slow one.
+type Timeouts struct {
+	// Connect bounds establishing the TCP connection. Defaults to DefaultConnectTimeout.
+	Connect time.Duration
+	// Read bounds waiting for the response headers once the request is sent. Defaults to DefaultReadTimeout.
+	Read time.Duration
+}
+
+// NewUpstreamTLSConfig builds the TLS settings of the requests to the upstreams.
+// The server certificates are verified against the CAs of the PEM file caBundlePath,
+// or against the system pool when it is empty, which may be minimal in containers.
+// insecureSkipVerify disables the verification, for local development against stubs only.
+func NewUpstreamTLSConfig(caBundlePath string, insecureSkipVerify bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+	if caBundlePath == "" {
+		return tlsConfig, nil
+	}
+	caBundle, err := os.ReadFile(caBundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA bundle: %w", err)
+	}
+	tlsConfig.RootCAs = x509.NewCertPool()
+	if !tlsConfig.RootCAs.AppendCertsFromPEM(caBundle) {
+		return nil, fmt.Errorf("invalid CA bundle %s: no PEM certificate found", caBundlePath)
+	}
+	return tlsConfig, nil
+}
+
+// newTransport clones http.DefaultTransport, resolving hosts through a DNS cache in front of resolver
+// that keeps the addresses for dnsCacheTTL, dnscache.DefaultTTL when it is not positive.
+// A nil resolver uses net.DefaultResolver and a nil tlsConfig the TLS settings of http.DefaultTransport.
+func newTransport(resolver dnscache.Resolver, dnsCacheTTL time.Duration, timeouts Timeouts, tlsConfig *tls.Config) *http.Transport {
+	if timeouts.Connect <= 0 {
+		timeouts.Connect = DefaultConnectTimeout
+	}
+	if timeouts.Read <= 0 {
+		timeouts.Read = DefaultReadTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{Timeout: timeouts.Connect, KeepAlive: 30 * time.Second}
+	transport.DialContext = dnscache.NewWithDialer(resolver, dnsCacheTTL, dialer).DialContext
+	transport.ResponseHeaderTimeout = timeouts.Read
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig.Clone()
+	}
+	return transport
+}
+
+// describeTimeout tells which timeout failed a request sent with ctx with err:
+// "request timeout" for the deadline of ctx, "connect timeout" or "read timeout" for the ones of the transport.
+// It returns an empty string when err is not a timeout.
+func describeTimeout(ctx context.Context, err error) string {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "request timeout"
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" && opErr.Timeout() {
+		return "connect timeout"
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "read timeout"
+	}
+	return ""
+}
+
+// canceledError tells the cancellation of ctx apart from the other errors of a call sent with it:
+// it wraps err in ErrRequestCanceled when ctx was canceled, and returns err otherwise.
+// A deadline exceeded is a timeout, not a cancellation.
+func canceledError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return fmt.Errorf("%w: %w", ErrRequestCanceled, err)
+	}
+	return err
+}
+
+// timeoutSuffix formats the timeout that failed a request for its log message, see describeTimeout.
+func timeoutSuffix(ctx context.Context, err error) string {
+	if timeout := describeTimeout(ctx, err); timeout != "" {
+		return " (" + timeout + ")"
+	}
+	return ""
+}
+
+// userAgent identifies the service to the upstreams, with the version set at build time
+// through the ldflags of the otel package, e.g. go-tracing/v1.2.3 (+https://github.com/leoseiji/go-tracing).
+func userAgent() string {
+	return "go-tracing/" + tracing.Version + " (+" + userAgentURL + ")"
+}
+
+// userAgentTransport sends the requests through next with the User-Agent of the service,
+// instead of Go's default Go-http-client/1.1.
+type userAgentTransport struct {
+	next http.RoundTripper
+}
+
+func newUserAgentTransport(next http.RoundTripper) *userAgentTransport {
+	return &userAgentTransport{next: next}
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request it is given.
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", userAgent())
+	return t.next.RoundTrip(req)
+}
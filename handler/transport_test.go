@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstreamRequestsCarryUserAgent(t *testing.T) {
+	tests := []struct {
+		name   string
+		lookup func(baseURL string) error
+	}{
+		{
+			name: "ViaCEP",
+			lookup: func(baseURL string) error {
+				_, err := NewViaCEPClient(ViaCEPConfig{BaseURL: baseURL}).GetLocation(context.Background(), "06233903")
+				return err
+			},
+		},
+		{
+			name: "WeatherAPI",
+			lookup: func(baseURL string) error {
+				_, err := NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: baseURL, APIKey: "test"}).GetWeather(context.Background(), "Osasco")
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var userAgentHeader string
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				userAgentHeader = r.Header.Get("User-Agent")
+				w.Write([]byte(`{"cep": "06233-903", "localidade": "Osasco", "current": {"temp_c": 25.0}}`))
+			}))
+			defer upstream.Close()
+
+			assert.NoError(t, tt.lookup(upstream.URL))
+			assert.Equal(t, "go-tracing/dev (+https://github.com/leoseiji/go-tracing)", userAgentHeader)
+		})
+	}
+}
+
+func TestDescribeTimeout(t *testing.T) {
+	slowUpstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slowUpstream.Close()
+	transport := newTransport(nil, 0, Timeouts{Read: 20 * time.Millisecond}, nil)
+	defer transport.CloseIdleConnections()
+	_, readErr := (&http.Client{Transport: transport}).Get(slowUpstream.URL)
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		err     error
+		timeout string
+	}{
+		{name: "Connect timeout", ctx: context.Background(), err: &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}, timeout: "connect timeout"},
+		{name: "Read timeout", ctx: context.Background(), err: readErr, timeout: "read timeout"},
+		{name: "Request timeout", ctx: expired, err: context.DeadlineExceeded, timeout: "request timeout"},
+		{name: "Connection refused", ctx: context.Background(), err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.err)
+			assert.Equal(t, tt.timeout, describeTimeout(tt.ctx, tt.err))
+		})
+	}
+}
+
+func TestNewUpstreamTLSConfig(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer upstream.Close()
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: upstream.Certificate().Raw})
+	if !assert.NoError(t, os.WriteFile(caBundle, certificate, 0o600)) {
+		return
+	}
+
+	tests := []struct {
+		name               string
+		caBundlePath       string
+		insecureSkipVerify bool
+		wantErr            bool
+	}{
+		{name: "System pool", wantErr: true},
+		{name: "CA bundle", caBundlePath: caBundle},
+		{name: "Insecure skip verify", insecureSkipVerify: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := NewUpstreamTLSConfig(tt.caBundlePath, tt.insecureSkipVerify)
+			if !assert.NoError(t, err) {
+				return
+			}
+			transport := newTransport(nil, 0, Timeouts{}, tlsConfig)
+			defer transport.CloseIdleConnections()
+			resp, err := (&http.Client{Transport: transport}).Get(upstream.URL)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+			}
+		})
+	}
+
+	_, err := NewUpstreamTLSConfig(filepath.Join(t.TempDir(), "missing.pem"), false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,26 @@
+package handler
+
+import "net/http"
+
+// APIVersion prefixes the paths of the API, e.g. /v1/weather-service-b/{cep}.
+// Breaking changes to the DTOs are served under a new version.
+const APIVersion = "v1"
+
+// apiPath returns path under the current APIVersion.
+func apiPath(path string) string {
+	return "/" + APIVersion + path
+}
+
+// redirectToAPIVersion redirects the requests to the unversioned paths, served before APIVersion was introduced,
+// to the same path under APIVersion: with 301 Moved Permanently as asked by the clients, and with
+// 308 Permanent Redirect for the methods other than GET and HEAD, which must not be changed into a GET.
+func redirectToAPIVersion(w http.ResponseWriter, r *http.Request) {
+	target := *r.URL
+	target.Path = apiPath(r.URL.Path)
+	target.RawPath = ""
+	status := http.StatusMovedPermanently
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		status = http.StatusPermanentRedirect
+	}
+	http.Redirect(w, r, target.String(), status)
+}
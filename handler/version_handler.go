@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/leoseiji/go-tracing/dto"
+)
+
+// NewVersionHandler serves the build of the service.
+// An empty version is reported as "dev" and an empty commit as "unknown".
+func NewVersionHandler(version dto.VersionResponse) http.HandlerFunc {
+	if version.Version == "" {
+		version.Version = "dev"
+	}
+	if version.Commit == "" {
+		version.Commit = "unknown"
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(version)
+	}
+}
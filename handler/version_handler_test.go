@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/leoseiji/go-tracing/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionHandlerReturnsValidJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/version", nil)
+	rr := httptest.NewRecorder()
+	// Without ldflags the build is unknown.
+	NewVersionHandler(dto.VersionResponse{})(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var body struct {
+		Version string `json:"version"`
+		Commit  string `json:"commit"`
+		BuiltAt string `json:"built_at"`
+	}
+	if !assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body)) {
+		return
+	}
+	assert.Equal(t, "dev", body.Version)
+	assert.NotEmpty(t, body.Commit)
+	_, err := time.Parse(time.RFC3339, body.BuiltAt)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/leoseiji/go-tracing/dto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultBatchMaxConcurrency bounds the concurrent service B calls of a batch lookup
+// when ServiceAConfig.BatchMaxConcurrency is not set.
+const DefaultBatchMaxConcurrency = 10
+
+var ErrEmptyBatch = fmt.Errorf("ceps must not be empty")
+
+// PostWeatherBatchHandler looks up the weather of every CEP in the request at once.
+// The CEPs are sent to service B concurrently, at most BatchMaxConcurrency at a time,
+// and failures are reported per CEP next to the successful lookups,
+// with 207 Multi-Status when any CEP failed.
+func (h *ServiceAHandler) PostWeatherBatchHandler(w http.ResponseWriter, r *http.Request) {
+	carrier := propagation.HeaderCarrier(r.Header)
+	ctx := r.Context()
+	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+
+	tracer := otel.Tracer("weather-service-a")
+	ctx, span := tracer.Start(ctx, "PostWeatherBatchHandler")
+	defer span.End()
+
+	var batchRequest dto.WeatherBatchRequest
+	if !decodeJSONBody(w, r, &batchRequest) {
+		return
+	}
+	if len(batchRequest.CEPs) == 0 {
+		http.Error(w, ErrEmptyBatch.Error(), http.StatusBadRequest)
+		return
+	}
+	span.SetAttributes(attribute.Int("batch.size", len(batchRequest.CEPs)))
+
+	// Each lookup writes only its own slot, so no locking is needed.
+	results := make([]*dto.CEPWeatherResponse, len(batchRequest.CEPs))
+	errs := make([]error, len(batchRequest.CEPs))
+	var g errgroup.Group
+	g.SetLimit(h.batchMaxConcurrency)
+	for i, cep := range batchRequest.CEPs {
+		if !h.cepValidation.isCepValid(ctx, cep) {
+			errs[i] = ErrCEPInvalid
+			continue
+		}
+		g.Go(func() error {
+			results[i], errs[i] = h.serviceBClient.GetWeather(ctx, cep)
+			return nil
+		})
+	}
+	g.Wait()
+
+	batchResponse := dto.WeatherBatchResponse{
+		Results: []dto.WeatherBatchResult{},
+		Errors:  []dto.WeatherBatchError{},
+	}
+	for i, cep := range batchRequest.CEPs {
+		if errs[i] != nil {
+			batchResponse.Errors = append(batchResponse.Errors, newWeatherBatchError(cep, errs[i]))
+			continue
+		}
+		batchResponse.Results = append(batchResponse.Results, dto.WeatherBatchResult{CEP: cep, Weather: results[i]})
+	}
+
+	// 207 Multi-Status tells clients to check every CEP when any of them failed.
+	status := http.StatusOK
+	if len(batchResponse.Errors) > 0 {
+		status = http.StatusMultiStatus
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(batchResponse)
+}
+
+// newWeatherBatchError reports err the way PostWeatherHandler would answer it.
+func newWeatherBatchError(cep string, err error) dto.WeatherBatchError {
+	switch {
+	case errors.Is(err, ErrCEPInvalid):
+		return dto.WeatherBatchError{CEP: cep, Status: http.StatusUnprocessableEntity, Error: ErrCEPInvalid.Error()}
+	case errors.Is(err, ErrCEPNotFound):
+		return dto.WeatherBatchError{CEP: cep, Status: http.StatusNotFound, Error: ErrCEPNotFound.Error()}
+	default:
+		log.Printf("error while making request: %s", err)
+		return dto.WeatherBatchError{CEP: cep, Status: http.StatusInternalServerError, Error: ErrInternalServerError.Error()}
+	}
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leoseiji/go-tracing/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostWeatherBatchHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		// response is only checked when set.
+		response string
+	}{
+		{
+			name:   "Partial failure",
+			body:   `{"ceps": ["06233903", "123", "12345678", "06233903"]}`,
+			status: http.StatusMultiStatus,
+			response: `{
+				"results": [
+					{"cep": "06233903", "weather": ` + weatherResponseFixture + `},
+					{"cep": "06233903", "weather": ` + weatherResponseFixture + `}
+				],
+				"errors": [
+					{"cep": "123", "status": 422, "error": "invalid zipcode"},
+					{"cep": "12345678", "status": 404, "error": "can not find zipcode"}
+				]
+			}`,
+		},
+		{
+			name:     "All failed",
+			body:     `{"ceps": ["abcdefgh"]}`,
+			status:   http.StatusMultiStatus,
+			response: `{"results": [], "errors": [{"cep": "abcdefgh", "status": 422, "error": "invalid zipcode"}]}`,
+		},
+		{name: "Empty batch", body: `{"ceps": []}`, status: http.StatusBadRequest},
+		{name: "Malformed body", body: `{"ceps": "06233903"}`, status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifyNoLeaks(t)
+			viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+			serviceB := newServiceB(t, viaCEP, weatherAPI, make(chan string, 10))
+			serviceA := NewServiceAHandler(ServiceAConfig{ServiceBURL: serviceB.URL})
+
+			req := httptest.NewRequest(http.MethodPost, "/weather/batch", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			serviceA.PostWeatherBatchHandler(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			if tt.response != "" {
+				assert.JSONEq(t, tt.response, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestPostWeatherBatchHandlerSharesTrace(t *testing.T) {
+	verifyNoLeaks(t)
+	recorder := newSpanRecorder(t)
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	serviceB := newServiceB(t, viaCEP, weatherAPI, make(chan string, 10))
+	serviceA := NewServiceAHandler(ServiceAConfig{ServiceBURL: serviceB.URL, BatchMaxConcurrency: 2})
+
+	req := httptest.NewRequest(http.MethodPost, "/weather/batch", strings.NewReader(`{"ceps": ["06233903", "12345678", "06233903"]}`))
+	rr := httptest.NewRecorder()
+	serviceA.PostWeatherBatchHandler(rr, req)
+
+	assert.Equal(t, http.StatusMultiStatus, rr.Code)
+	spans := recorder.Ended()
+	batchSpan := findSpan(spans, "PostWeatherBatchHandler")
+	if !assert.NotNil(t, batchSpan) {
+		return
+	}
+	var serviceBSpans int
+	for _, span := range spans {
+		if span.Name() != "GetWeatherHandler" {
+			continue
+		}
+		serviceBSpans++
+		assert.Equal(t, batchSpan.SpanContext().TraceID(), span.SpanContext().TraceID())
+		assert.Equal(t, batchSpan.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+	assert.Equal(t, 3, serviceBSpans)
+}
+
+func TestBatchHandlerPartialFailure(t *testing.T) {
+	verifyNoLeaks(t)
+	viaCEP, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	serviceB := newServiceB(t, viaCEP, weatherAPI, make(chan string, 10))
+	serviceA := NewServiceAHandler(ServiceAConfig{ServiceBURL: serviceB.URL})
+
+	req := httptest.NewRequest(http.MethodPost, "/weather/batch", strings.NewReader(`{"ceps": ["06233903", "0623", "06233903", "cep", "06233903"]}`))
+	rr := httptest.NewRecorder()
+	serviceA.PostWeatherBatchHandler(rr, req)
+
+	assert.Equal(t, http.StatusMultiStatus, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var batchResponse dto.WeatherBatchResponse
+	if !assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &batchResponse)) {
+		return
+	}
+	assert.Len(t, batchResponse.Results, 3)
+	assert.Len(t, batchResponse.Errors, 2)
+}
+
+func TestBatchHandlerRespectsMaxConcurrency(t *testing.T) {
+	verifyNoLeaks(t)
+	const maxConcurrency = 3
+
+	var (
+		mu                     sync.Mutex
+		inFlight               int
+		maxConcurrencyObserved int
+	)
+	viaCEP := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxConcurrencyObserved = max(maxConcurrencyObserved, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		// Hold the call so the next lookups of the batch start meanwhile.
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"cep": "06233-903", "localidade": "Osasco"}`))
+	}))
+	t.Cleanup(viaCEP.Close)
+	_, weatherAPI := newUpstreams(t, http.StatusOK, http.StatusOK)
+	serviceB := newServiceB(t, viaCEP, weatherAPI, make(chan string, 10))
+	serviceA := NewServiceAHandler(ServiceAConfig{ServiceBURL: serviceB.URL, BatchMaxConcurrency: maxConcurrency})
+
+	ceps := `["06233903","06233903","06233903","06233903","06233903","06233903","06233903","06233903","06233903","06233903"]`
+	req := httptest.NewRequest(http.MethodPost, "/weather/batch", strings.NewReader(`{"ceps": `+ceps+`}`))
+	rr := httptest.NewRecorder()
+	serviceA.PostWeatherBatchHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var batchResponse dto.WeatherBatchResponse
+	if assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &batchResponse)) {
+		assert.Len(t, batchResponse.Results, 10)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, maxConcurrency, maxConcurrencyObserved)
+}
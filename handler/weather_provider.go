@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/leoseiji/go-tracing/dnscache"
+	"github.com/leoseiji/go-tracing/dto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"golang.org/x/net/http2"
+)
+
+// WeatherProvider fetches the current weather for a location name.
+type WeatherProvider interface {
+	GetWeather(ctx context.Context, location string) (*dto.Weather, error)
+}
+
+// DefaultWeatherAPIBaseURL is the WeatherAPI used when WeatherAPIConfig.BaseURL is empty.
+const DefaultWeatherAPIBaseURL = "https://api.weatherapi.com"
+
+type WeatherAPIConfig struct {
+	// BaseURL of the WeatherAPI, without a trailing slash. Defaults to DefaultWeatherAPIBaseURL.
+	// Point it at a local stub or a staging environment to run without reaching weatherapi.com.
+	BaseURL string
+	APIKey  string
+	// HTTPClient used for the requests. Defaults to NewWeatherAPIClient(Resolver, DNSCacheTTL, Timeouts, TLSConfig).
+	HTTPClient *http.Client
+	// Resolver used for DNS lookups by the default HTTPClient, whose answers are cached.
+	// Defaults to net.DefaultResolver.
+	Resolver dnscache.Resolver
+	// DNSCacheTTL is how long the default HTTPClient caches the addresses of the hosts. Defaults to dnscache.DefaultTTL.
+	DNSCacheTTL time.Duration
+	// Timeouts of the connections and responses of the default HTTPClient.
+	Timeouts Timeouts
+	// TLSConfig of the default HTTPClient, see NewUpstreamTLSConfig. Defaults to the system settings.
+	TLSConfig *tls.Config
+	// MeterProvider records the lookup latencies. Defaults to the global MeterProvider.
+	MeterProvider metric.MeterProvider
+	// DeadLetterLogger records the failed lookups of the current weather. Optional.
+	DeadLetterLogger *DeadLetterLogger
+}
+
+// weatherAPIMaxIdleConnsPerHost bounds the idle connections kept open to WeatherAPI.
+const weatherAPIMaxIdleConnsPerHost = 10
+
+// NewWeatherAPIClient creates an HTTP client with its own transport for WeatherAPI,
+// so keep-alive connections to its endpoint are reused across requests
+// instead of competing with other hosts in http.DefaultClient's pool.
+// Host names are resolved through a DNS cache in front of resolver, which may be nil, kept for dnsCacheTTL,
+// connections and responses are bounded by timeouts and secured by tlsConfig, which may be nil.
+// HTTP/2 is enabled so concurrent requests to WeatherAPI share connections.
+// Requests are sent with the User-Agent of the service.
+func NewWeatherAPIClient(resolver dnscache.Resolver, dnsCacheTTL time.Duration, timeouts Timeouts, tlsConfig *tls.Config) *http.Client {
+	transport := newTransport(resolver, dnsCacheTTL, timeouts, tlsConfig)
+	transport.DisableKeepAlives = false
+	transport.MaxIdleConnsPerHost = weatherAPIMaxIdleConnsPerHost
+	if err := http2.ConfigureTransport(transport); err != nil {
+		log.Printf("error configuring HTTP/2 for weatherAPI, falling back to HTTP/1.1. Err:%s", err.Error())
+	}
+	return &http.Client{Transport: newUserAgentTransport(transport)}
+}
+
+// WeatherAPIProvider is a WeatherProvider backed by the WeatherAPI HTTP API.
+type WeatherAPIProvider struct {
+	baseURL     string
+	apiKey      string
+	httpClient  *http.Client
+	deadLetters *DeadLetterLogger
+
+	lookupDuration metric.Float64Histogram
+}
+
+func NewWeatherAPIProvider(cfg WeatherAPIConfig) *WeatherAPIProvider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultWeatherAPIBaseURL
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = NewWeatherAPIClient(cfg.Resolver, cfg.DNSCacheTTL, cfg.Timeouts, cfg.TLSConfig)
+	}
+	return &WeatherAPIProvider{
+		baseURL:        cfg.BaseURL,
+		apiKey:         cfg.APIKey,
+		httpClient:     cfg.HTTPClient,
+		deadLetters:    cfg.DeadLetterLogger,
+		lookupDuration: newLatencyHistogram(cfg.MeterProvider, "weather_lookup_duration_ms", "Duration of the current weather lookups on WeatherAPI."),
+	}
+}
+
+// Ping reports whether WeatherAPI is reachable.
+func (p *WeatherAPIProvider) Ping(ctx context.Context) error {
+	return ping(ctx, p.httpClient, p.baseURL)
+}
+
+// ErrWeatherAPIKeyInvalid is returned by ValidateKey when WeatherAPI rejects the API key.
+var ErrWeatherAPIKeyInvalid = fmt.Errorf("invalid weatherAPI key")
+
+// ValidateKey checks the API key with a cheap timezone lookup,
+// returning ErrWeatherAPIKeyInvalid when WeatherAPI rejects it.
+func (p *WeatherAPIProvider) ValidateKey(ctx context.Context) error {
+	reqUrl := fmt.Sprintf("%s/v1/timezone.json?key=%s&q=%s", p.baseURL, url.QueryEscape(p.apiKey), url.QueryEscape("Sao Paulo"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqUrl, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return redactURLError(err, fmt.Sprintf("%s/v1/timezone.json", p.baseURL))
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrWeatherAPIKeyInvalid
+	default:
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+}
+
+// redactURLError replaces the URL of err, when it is the *url.Error of a failed call to WeatherAPI,
+// with redactedURL: the URL of the call carries the API key in its query.
+func redactURLError(err error, redactedURL string) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = redactedURL
+	}
+	return err
+}
+
+func (p *WeatherAPIProvider) GetWeather(ctx context.Context, location string) (_ *dto.Weather, err error) {
+	tracer := otel.Tracer("weather-service-b-get-weather-by-location")
+	_, span := tracer.Start(ctx, "getWeatherByLocation")
+	defer span.End()
+	start := time.Now()
+	defer func() {
+		p.lookupDuration.Record(ctx, durationInMilliseconds(time.Since(start)))
+	}()
+
+	reqUrl := fmt.Sprintf("%s/v1/current.json?key=%s&q=%s", p.baseURL, url.QueryEscape(p.apiKey), url.QueryEscape(location))
+	// The API key is left out of the errors and of the dead-letter record.
+	redactedURL := fmt.Sprintf("%s/v1/current.json?q=%s", p.baseURL, url.QueryEscape(location))
+	defer func() {
+		if err != nil && !errors.Is(err, ErrRequestCanceled) {
+			p.deadLetters.recordSingleAttempt(ctx, DeadLetter{Service: "weatherapi", Location: location, URL: redactedURL}, start, err)
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		return nil, canceledError(ctx, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqUrl, nil)
+	if err != nil {
+		err = redactURLError(err, redactedURL)
+		log.Printf("error creating weatherAPI request. Err:%s", err.Error())
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		err = redactURLError(err, redactedURL)
+		log.Printf("error executing weatherAPI request%s. Err:%s", timeoutSuffix(ctx, err), err.Error())
+		return nil, canceledError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		log.Printf("error while getting weatherAPI result. Status: %s, Body: %s", resp.Status, string(body))
+
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("error while reading weatherAPI result. Err:%s", err.Error())
+		return nil, err
+	}
+
+	var weather *dto.Weather
+	if err = json.Unmarshal(body, &weather); err != nil {
+		log.Printf("error while converting weatherAPI result. Err:%s", err.Error())
+		return nil, err
+	}
+	span.SetAttributes(weatherTempCKey.Float64(weather.Current.TempC))
+	return weather, nil
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeatherAPIProviderEncodesLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		rawQuery string
+	}{
+		{name: "Single word", location: "Osasco", rawQuery: "key=test&q=Osasco"},
+		{name: "Space", location: "Rio de Janeiro", rawQuery: "key=test&q=Rio+de+Janeiro"},
+		{name: "Accented characters", location: "São Paulo", rawQuery: "key=test&q=S%C3%A3o+Paulo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rawQuery, location string
+			weatherAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				rawQuery = r.URL.RawQuery
+				location = r.URL.Query().Get("q")
+				w.Write([]byte(`{"current": {"temp_c": 25.0}}`))
+			}))
+			defer weatherAPI.Close()
+
+			provider := NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"})
+			_, err := provider.GetWeather(context.Background(), tt.location)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.rawQuery, rawQuery)
+			assert.Equal(t, tt.location, location)
+		})
+	}
+}
+
+func TestWeatherAPIProviderRequestURL(t *testing.T) {
+	var host, requestURI string
+	weatherAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		requestURI = r.RequestURI
+		w.Write([]byte(`{"current": {"temp_c": 25.0}}`))
+	}))
+	defer weatherAPI.Close()
+
+	provider := NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"})
+	_, err := provider.GetWeather(context.Background(), "Osasco")
+
+	assert.NoError(t, err)
+	assert.Equal(t, weatherAPI.URL, "http://"+host)
+	assert.Equal(t, "/v1/current.json?key=test&q=Osasco", requestURI)
+}
+
+func TestWeatherAPIProviderValidateKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		err    error
+	}{
+		{name: "Valid key", status: http.StatusOK},
+		{name: "Invalid key", status: http.StatusUnauthorized, err: ErrWeatherAPIKeyInvalid},
+		{name: "Disabled key", status: http.StatusForbidden, err: ErrWeatherAPIKeyInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestURI string
+			weatherAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestURI = r.RequestURI
+				w.WriteHeader(tt.status)
+			}))
+			defer weatherAPI.Close()
+
+			provider := NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"})
+			err := provider.ValidateKey(context.Background())
+
+			assert.Equal(t, "/v1/timezone.json?key=test&q=Sao+Paulo", requestURI)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+// benchmarkWeatherAPIProvider runs concurrent lookups against a local TLS stub
+// speaking both HTTP/1.1 and HTTP/2, through the transport set up by configure.
+func benchmarkWeatherAPIProvider(b *testing.B, configure func(transport *http.Transport)) {
+	weatherAPI := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"current": {"temp_c": 25.0}}`))
+	}))
+	weatherAPI.EnableHTTP2 = true
+	weatherAPI.StartTLS()
+	defer weatherAPI.Close()
+
+	client := NewWeatherAPIClient(nil, 0, Timeouts{}, nil)
+	transport := client.Transport.(*userAgentTransport).next.(*http.Transport)
+	configure(transport)
+	transport.TLSClientConfig.RootCAs = weatherAPI.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	provider := NewWeatherAPIProvider(WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test", HTTPClient: client})
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := provider.GetWeather(context.Background(), "Osasco"); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkWeatherAPIProviderHTTP1(b *testing.B) {
+	benchmarkWeatherAPIProvider(b, func(transport *http.Transport) {
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		transport.TLSClientConfig.NextProtos = []string{"http/1.1"}
+	})
+}
+
+func BenchmarkWeatherAPIProviderHTTP2(b *testing.B) {
+	benchmarkWeatherAPIProvider(b, func(*http.Transport) {})
+}
@@ -0,0 +1,230 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leoseiji/go-tracing/handler"
+	"github.com/leoseiji/go-tracing/middleware"
+	"github.com/leoseiji/go-tracing/otel"
+	"github.com/stretchr/testify/assert"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// jaegerImage is the Jaeger all-in-one image receiving the spans over OTLP/HTTP.
+const jaegerImage = "jaegertracing/all-in-one:1.57"
+
+// jaegerTrace is a trace returned by the query API of Jaeger.
+type jaegerTrace struct {
+	TraceID string       `json:"traceID"`
+	Spans   []jaegerSpan `json:"spans"`
+}
+
+type jaegerSpan struct {
+	SpanID        string `json:"spanID"`
+	OperationName string `json:"operationName"`
+	References    []struct {
+		RefType string `json:"refType"`
+		SpanID  string `json:"spanID"`
+	} `json:"references"`
+	Tags []struct {
+		Key   string `json:"key"`
+		Value any    `json:"value"`
+	} `json:"tags"`
+}
+
+// startJaeger runs Jaeger for the duration of the test and returns its OTLP/HTTP endpoint and the URL of its query API.
+func startJaeger(t *testing.T) (otlpEndpoint, queryURL string) {
+	t.Helper()
+
+	ctx := context.Background()
+	jaeger, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        jaegerImage,
+			ExposedPorts: []string{"4318/tcp", "16686/tcp"},
+			Env:          map[string]string{"COLLECTOR_OTLP_ENABLED": "true"},
+			WaitingFor:   wait.ForHTTP("/").WithPort("16686/tcp"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatalf("starting Jaeger: %s", err)
+	}
+	t.Cleanup(func() { jaeger.Terminate(ctx) })
+
+	host, err := jaeger.Host(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otlpPort, err := jaeger.MappedPort(ctx, "4318/tcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	queryPort, err := jaeger.MappedPort(ctx, "16686/tcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf("http://%s:%s", host, otlpPort.Port()), fmt.Sprintf("http://%s:%s", host, queryPort.Port())
+}
+
+// newUpstreams stubs ViaCEP and WeatherAPI, so that only Jaeger runs outside of the test.
+func newUpstreams(t *testing.T) (viaCEP, weatherAPI *httptest.Server) {
+	t.Helper()
+
+	viaCEP = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"cep": "06233-903", "localidade": "Osasco"}`))
+	}))
+	t.Cleanup(viaCEP.Close)
+	weatherAPI = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"current": {"temp_c": 28.5}}`))
+	}))
+	t.Cleanup(weatherAPI.Close)
+	return viaCEP, weatherAPI
+}
+
+// getTrace polls the query API of Jaeger for the trace of the span tagged with the request ID requestID
+// until it holds the span named last or timeout passes.
+func getTrace(t *testing.T, queryURL, serviceName, requestID, last string, timeout time.Duration) jaegerTrace {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		if jaegerTrace, ok := queryTrace(queryURL, serviceName, requestID); ok {
+			for _, span := range jaegerTrace.Spans {
+				if span.OperationName == last {
+					return jaegerTrace
+				}
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("trace of request %s did not reach Jaeger within %s", requestID, timeout)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func queryTrace(queryURL, serviceName, requestID string) (jaegerTrace, bool) {
+	query := url.Values{
+		"service": {serviceName},
+		"tags":    {fmt.Sprintf(`{"request.id": %q}`, requestID)},
+	}
+	resp, err := http.Get(queryURL + "/api/traces?" + query.Encode())
+	if err != nil {
+		return jaegerTrace{}, false
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Data []jaegerTrace `json:"data"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&body) != nil || len(body.Data) == 0 {
+		return jaegerTrace{}, false
+	}
+	return body.Data[0], true
+}
+
+func findSpan(jaegerTrace jaegerTrace, operationName string) *jaegerSpan {
+	for i := range jaegerTrace.Spans {
+		if jaegerTrace.Spans[i].OperationName == operationName {
+			return &jaegerTrace.Spans[i]
+		}
+	}
+	return nil
+}
+
+// findTaggedSpan returns the span of jaegerTrace whose tag key is value.
+func findTaggedSpan(jaegerTrace jaegerTrace, key string, value any) *jaegerSpan {
+	for i := range jaegerTrace.Spans {
+		if tagValue(jaegerTrace.Spans[i], key) == value {
+			return &jaegerTrace.Spans[i]
+		}
+	}
+	return nil
+}
+
+// tagValue returns the value of the tag key of span, nil when it has none.
+func tagValue(span jaegerSpan, key string) any {
+	for _, tag := range span.Tags {
+		if tag.Key == key {
+			return tag.Value
+		}
+	}
+	return nil
+}
+
+// parentIDs returns the IDs of the parents of span.
+func parentIDs(span jaegerSpan) []string {
+	var ids []string
+	for _, reference := range span.References {
+		if reference.RefType == "CHILD_OF" {
+			ids = append(ids, reference.SpanID)
+		}
+	}
+	return ids
+}
+
+func TestTracesArriveInJaeger(t *testing.T) {
+	otlpEndpoint, queryURL := startJaeger(t)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", otlpEndpoint)
+	const serviceName = "go-tracing-integration"
+	t.Setenv("OTEL_SERVICE_NAME", serviceName)
+	ctx := context.Background()
+	otelConfig, err := otel.LoadConfig()
+	if !assert.NoError(t, err) {
+		return
+	}
+	otelShutdown, err := otel.SetupOTelSDK(ctx, otelConfig)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	viaCEP, weatherAPI := newUpstreams(t)
+	serviceB := handler.NewServiceBHandler(handler.ServiceBConfig{
+		CEPClient:       handler.NewViaCEPClient(handler.ViaCEPConfig{BaseURL: viaCEP.URL}),
+		WeatherProvider: handler.NewWeatherAPIProvider(handler.WeatherAPIConfig{BaseURL: weatherAPI.URL, APIKey: "test"}),
+	})
+	serviceBServer := httptest.NewServer(handler.NewRouter(handler.RouterConfig{ServiceB: serviceB}))
+	t.Cleanup(serviceBServer.Close)
+	serviceA := handler.NewServiceAHandler(handler.ServiceAConfig{ServiceBURL: serviceBServer.URL})
+	serviceAServer := httptest.NewServer(handler.NewRouter(handler.RouterConfig{ServiceA: serviceA, ServiceB: serviceB}))
+	t.Cleanup(serviceAServer.Close)
+
+	// The request carries no trace context, so that service A starts the trace.
+	// It is found in Jaeger by the request ID recorded on the span of service A.
+	resp, err := http.Post(serviceAServer.URL+"/v1/weather-service-a", "application/json", strings.NewReader(`{"cep": "06233903"}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	requestID := resp.Header.Get(middleware.RequestIDHeader)
+	if !assert.NotEmpty(t, requestID) {
+		return
+	}
+	// Shutting down exports the spans still batched.
+	shutdownCtx, cancel := context.WithTimeout(ctx, otel.DefaultShutdownTimeout)
+	defer cancel()
+	assert.NoError(t, otelShutdown(shutdownCtx))
+
+	jaegerTrace := getTrace(t, queryURL, serviceName, requestID, "GetWeatherHandler", 30*time.Second)
+	serviceARootSpan := findTaggedSpan(jaegerTrace, "request.id", requestID)
+	serviceASpan := findSpan(jaegerTrace, "PostWeatherHandler")
+	serviceBSpan := findSpan(jaegerTrace, "GetWeatherHandler")
+	if !assert.NotNil(t, serviceARootSpan, "service A root span") || !assert.NotNil(t, serviceASpan, "service A span") ||
+		!assert.NotNil(t, serviceBSpan, "service B span") {
+		return
+	}
+	assert.Empty(t, serviceARootSpan.References, "the span of service A is the root of the trace")
+	assert.Contains(t, parentIDs(*serviceASpan), serviceARootSpan.SpanID)
+	assert.Contains(t, parentIDs(*serviceBSpan), serviceASpan.SpanID)
+	assert.Equal(t, "06233903", tagValue(*serviceBSpan, "cep"))
+}
@@ -2,17 +2,23 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
+	"net/http/pprof"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/leoseiji/go-tracing/config"
+	"github.com/leoseiji/go-tracing/dto"
 	"github.com/leoseiji/go-tracing/handler"
 	"github.com/leoseiji/go-tracing/otel"
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 func main() {
@@ -22,37 +28,134 @@ func main() {
 }
 
 func run() (err error) {
-	// Handle SIGINT (CTRL+C) gracefully.
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	// Handle SIGINT (CTRL+C) and SIGTERM gracefully.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// Read the configuration once, see config.Load for the environment variables.
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+	slog.SetLogLoggerLevel(cfg.LogLevel)
+
+	// Keep exemplars linking the metrics to the traces, unless the environment says otherwise.
+	if _, ok := os.LookupEnv(otel.ExemplarFeatureEnv); !ok {
+		os.Setenv(otel.ExemplarFeatureEnv, "true")
+	}
+
 	// Set up OpenTelemetry.
-	otelShutdown, err := otel.SetupOTelSDK(ctx)
+	otelShutdown, err := otel.SetupOTelSDK(ctx, cfg.OTel)
 	if err != nil {
 		return
 	}
 	// Handle shutdown properly so nothing leaks.
+	// The spans and metrics still buffered are flushed within the shutdown timeout.
 	defer func() {
-		err = errors.Join(err, otelShutdown(context.Background()))
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		err = errors.Join(err, otelShutdown(shutdownCtx))
 	}()
 
+	// Report runtime statistics.
+	if err = otel.NewProfiler(otel.DefaultProfilerInterval).Start(ctx); err != nil {
+		return
+	}
+
+	upstreamTLSConfig, err := newUpstreamTLSConfig(cfg)
+	if err != nil {
+		return
+	}
+	deadLetters, closeDeadLetters, err := newDeadLetterLogger(cfg.DeadLetterLogFile)
+	if err != nil {
+		return
+	}
+	defer func() {
+		err = errors.Join(err, closeDeadLetters())
+	}()
+	serviceB, upstreams := newServiceB(ctx, cfg, upstreamTLSConfig, deadLetters)
+	// SKIP_STARTUP_PROBE=true starts without reaching the upstreams, for local development and tests.
+	if !cfg.SkipStartupProbe {
+		if err = probeUpstreams(ctx, upstreams); err != nil {
+			return
+		}
+	}
+	serviceBClient, closeServiceBClient, err := newServiceBClient(cfg, upstreamTLSConfig)
+	if err != nil {
+		return
+	}
+	defer func() {
+		err = errors.Join(err, closeServiceBClient())
+	}()
+	serviceA := handler.NewServiceAHandler(handler.ServiceAConfig{
+		ServiceBClient:      serviceBClient,
+		BatchMaxConcurrency: cfg.BatchMaxConcurrency,
+	})
+
+	router := handler.NewRouter(handler.RouterConfig{
+		ServiceA:              serviceA,
+		ServiceB:              serviceB,
+		AllowedOrigins:        cfg.AllowedOrigins,
+		MaxRequestBodyBytes:   cfg.MaxRequestBodyBytes,
+		RequestTimeout:        cfg.RequestTimeout,
+		GzipMinSize:           cfg.GzipMinSize,
+		RateLimitRPS:          cfg.RateLimitRPS,
+		RateLimitBurst:        cfg.RateLimitBurst,
+		HealthChecks:          upstreams.healthChecks(),
+		AccessLogSampleRate:   cfg.AccessLogSampleRate,
+		AdminToken:            cfg.AdminToken,
+		CEPCache:              upstreams.cepCache,
+		Version:               buildVersion(),
+		InternalSecret:        cfg.InternalSecret,
+		InternalSignatureSkew: cfg.InternalSignatureSkew,
+	})
+
 	// Start HTTP server.
 	srv := &http.Server{
-		Addr:         ":8080",
+		Addr:         ":" + cfg.HTTPPort,
 		BaseContext:  func(_ net.Listener) context.Context { return ctx },
 		ReadTimeout:  time.Second,
 		WriteTimeout: 10 * time.Second,
-		Handler:      newHTTPHandler(),
+		Handler:      router,
 	}
-	srvErr := make(chan error, 1)
+	srvErr := make(chan error, 3)
 	go func() {
 		srvErr <- srv.ListenAndServe()
 	}()
 
+	// Start the pprof server when ENABLE_PPROF=true.
+	// WARNING: the profiles reveal the internals of the service and profiling slows it down,
+	// never enable it in production without network-level access controls on PPROF_PORT.
+	pprofSrv := &http.Server{Addr: ":" + cfg.PprofPort, Handler: newPprofMux()}
+	if cfg.EnablePprof {
+		if cfg.OTel.Environment == "production" {
+			log.Printf("WARNING: ENABLE_PPROF=true with ENVIRONMENT=production, make sure port %s is not publicly reachable", cfg.PprofPort)
+		}
+		go func() {
+			srvErr <- pprofSrv.ListenAndServe()
+		}()
+	}
+
+	// Start gRPC server.
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		return
+	}
+	grpcSrv := handler.NewWeatherGRPCServer(serviceB, handler.GRPCServerConfig{
+		InternalSecret:        cfg.InternalSecret,
+		InternalSignatureSkew: cfg.InternalSignatureSkew,
+	})
+	go func() {
+		srvErr <- grpcSrv.Serve(grpcListener)
+	}()
+
 	// Wait for interruption.
 	select {
 	case err = <-srvErr:
-		// Error when starting HTTP server.
+		// Error when starting HTTP or gRPC server.
+		grpcSrv.Stop()
+		_ = srv.Close()
+		_ = pprofSrv.Close()
 		return
 	case <-ctx.Done():
 		// Wait for first CTRL+C.
@@ -61,25 +164,156 @@ func run() (err error) {
 	}
 
 	// When Shutdown is called, ListenAndServe immediately returns ErrServerClosed.
-	err = srv.Shutdown(context.Background())
+	// GracefulStop waits for pending RPCs to finish, like Shutdown does for requests.
+	err = errors.Join(srv.Shutdown(context.Background()), pprofSrv.Shutdown(context.Background()))
+	grpcSrv.GracefulStop()
 	return
 }
 
-func newHTTPHandler() http.Handler {
+// newPprofMux serves the profiles of net/http/pprof under /debug/pprof/.
+// It is served on its own port, so that the profiles are not exposed with the API.
+func newPprofMux() *http.ServeMux {
 	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
 
-	// handleFunc is a replacement for mux.HandleFunc
-	// which enriches the handler's HTTP instrumentation with the pattern as the http.route.
-	handleFunc := func(pattern string, handlerFunc func(http.ResponseWriter, *http.Request)) {
-		// Configure the "http.route" for the HTTP instrumentation.
-		handler := otelhttp.WithRouteTag(pattern, http.HandlerFunc(handlerFunc))
-		mux.Handle(pattern, handler)
+// newUpstreamTLSConfig secures the requests to the upstreams with the CA bundle of cfg,
+// or without verifying their certificates, which config.Load refuses in production.
+func newUpstreamTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	return handler.NewUpstreamTLSConfig(cfg.TLSCABundle, cfg.InsecureSkipTLSVerify)
+}
+
+// newServiceB wires service B, whose business logic is shared by the HTTP and gRPC servers,
+// to its upstreams and returns it along with the clients of the upstreams.
+func newServiceB(ctx context.Context, cfg *config.Config, upstreamTLSConfig *tls.Config, deadLetters *handler.DeadLetterLogger) (*handler.ServiceBHandler, serviceBUpstreams) {
+	slowQueryLog := handler.NewSlowQueryLog(cfg.SlowQueryThreshold, nil)
+	timeouts := handler.Timeouts{Connect: cfg.ConnectTimeout, Read: cfg.ReadTimeout}
+	// An empty base URL is the production one of the upstream, see handler.DefaultViaCEPBaseURL and its siblings.
+	viaCEPClient := handler.NewViaCEPClient(handler.ViaCEPConfig{
+		BaseURL:     cfg.ViaCEPBaseURL,
+		Timeouts:    timeouts,
+		DNSCacheTTL: cfg.DNSCacheTTL,
+		TLSConfig:   upstreamTLSConfig,
+	})
+	// APICEP serves the lookups ViaCEP fails, the locations found are cached.
+	// The lookups failing on both are recorded in the dead-letter log.
+	cepCache := handler.NewCachingCEPClient(handler.NewFallbackCEPClient(viaCEPClient, handler.NewAPICEPClient(handler.APICEPConfig{
+		BaseURL:     cfg.APICEPBaseURL,
+		Timeouts:    timeouts,
+		DNSCacheTTL: cfg.DNSCacheTTL,
+		TLSConfig:   upstreamTLSConfig,
+	}), deadLetters), cfg.CEPCacheTTL, cfg.CEPCacheMaxEntries, nil)
+	weatherProvider := handler.NewWeatherAPIProvider(handler.WeatherAPIConfig{
+		BaseURL:          cfg.WeatherAPIBaseURL,
+		APIKey:           cfg.WeatherAPIKey,
+		Timeouts:         timeouts,
+		DNSCacheTTL:      cfg.DNSCacheTTL,
+		TLSConfig:        upstreamTLSConfig,
+		DeadLetterLogger: deadLetters,
+	})
+	precomputed := handler.NewPrecomputedWeather(handler.PopularCEPs, handler.DefaultPrecomputedRefreshInterval, cepCache, weatherProvider)
+	precomputed.Start(ctx)
+	serviceB := handler.NewServiceBHandler(handler.ServiceBConfig{
+		CEPClient:        cepCache,
+		WeatherProvider:  weatherProvider,
+		ForecastProvider: weatherProvider,
+		SlowQueryLog:     slowQueryLog,
+		Precomputed:      precomputed,
+	})
+	return serviceB, serviceBUpstreams{viaCEP: viaCEPClient, cepCache: cepCache, weatherAPI: weatherProvider}
+}
+
+// serviceBUpstreams are the clients of the upstreams of service B.
+type serviceBUpstreams struct {
+	viaCEP     *handler.ViaCEPClient
+	cepCache   *handler.CachingCEPClient
+	weatherAPI *handler.WeatherAPIProvider
+}
+
+// healthChecks are the checks of the upstreams run by /health?ready=true.
+func (u serviceBUpstreams) healthChecks() map[string]handler.HealthCheck {
+	return map[string]handler.HealthCheck{
+		"viacep":     u.viaCEP.Ping,
+		"weatherapi": u.weatherAPI.Ping,
 	}
+}
 
-	handleFunc("/weather-service-a", handler.PostWeatherHandler)
-	handleFunc("/weather-service-b/{cep}", handler.GetWeatherHandler)
+// startupProbeCEP is a CEP known to ViaCEP, looked up by the startup probe.
+const startupProbeCEP = "01310100"
 
-	// Add HTTP instrumentation for the whole server.
-	handler := otelhttp.NewHandler(mux, "/")
-	return handler
+// probeUpstreams checks that service B can serve requests before it starts:
+// WeatherAPI must accept the API key and ViaCEP must find startupProbeCEP.
+// The error tells the operator what to fix.
+func probeUpstreams(ctx context.Context, upstreams serviceBUpstreams) error {
+	ctx, cancel := context.WithTimeout(ctx, handler.DefaultViaCEPTimeout)
+	defer cancel()
+	if err := upstreams.weatherAPI.ValidateKey(ctx); err != nil {
+		return fmt.Errorf("startup probe: WeatherAPI check failed, verify the API key and WEATHER_API_BASE_URL, "+
+			"or set SKIP_STARTUP_PROBE=true to start without it: %w", err)
+	}
+	// ViaCEP is reached directly, so that the probe neither falls back to APICEP nor fills the cache.
+	if _, err := upstreams.viaCEP.GetLocation(ctx, startupProbeCEP); err != nil {
+		return fmt.Errorf("startup probe: ViaCEP lookup of %s failed, verify VIACEP_BASE_URL and the network access to it, "+
+			"or set SKIP_STARTUP_PROBE=true to start without it: %w", startupProbeCEP, err)
+	}
+	return nil
+}
+
+// newDeadLetterLogger records the failed upstream calls in the file at path,
+// appending to it, or on stderr when path is empty. closeLogger closes the file.
+func newDeadLetterLogger(path string) (logger *handler.DeadLetterLogger, closeLogger func() error, err error) {
+	if path == "" {
+		return handler.NewDeadLetterLogger(os.Stderr), func() error { return nil }, nil
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+	return handler.NewDeadLetterLogger(file), file.Close, nil
+}
+
+// newServiceBClient creates the client service A calls service B with, over the transport of cfg.
+// The HTTP requests and the RPCs are signed with the internal secret, unless it is empty.
+// The HTTP requests are secured by tlsConfig.
+// They use HTTP/2 over TLS, unless DISABLE_HTTP2 is true for proxies not supporting it.
+// closeClient releases the client.
+func newServiceBClient(cfg *config.Config, tlsConfig *tls.Config) (client handler.ServiceBClient, closeClient func() error, err error) {
+	switch cfg.ServiceBTransport {
+	case config.TransportHTTP:
+		client = handler.NewHTTPServiceBClient(handler.HTTPServiceBClientConfig{
+			ServiceBURL:    cfg.ServiceBURL,
+			HTTPClient:     handler.NewServiceBHTTPClient(tlsConfig, cfg.DisableHTTP2),
+			RetryOn503:     true,
+			InternalSecret: cfg.InternalSecret,
+		})
+		return client, func() error { return nil }, nil
+	case config.TransportGRPC:
+		grpcConfig := handler.GRPCServiceBClientConfig{Target: cfg.ServiceBGRPCTarget, InternalSecret: cfg.InternalSecret}
+		if cfg.ServiceBGRPCTLS {
+			grpcConfig.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		grpcClient, err := handler.NewGRPCServiceBClient(grpcConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		return grpcClient, grpcClient.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("invalid SERVICE_B_TRANSPORT %q: expected http or grpc", cfg.ServiceBTransport)
+	}
+}
+
+// buildVersion describes the build set by the ldflags of the otel package.
+// A missing or invalid build time is reported as the zero time.
+func buildVersion() dto.VersionResponse {
+	builtAt, _ := time.Parse(time.RFC3339, otel.BuiltAt)
+	return dto.VersionResponse{
+		Version: otel.Version,
+		Commit:  otel.Commit,
+		BuiltAt: builtAt,
+	}
 }
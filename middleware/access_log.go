@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AccessLogMiddleware logs the requests once they are served and feeds their latency to tracker.
+// Records carry the IDs of the request span and the request ID, to correlate them with the trace,
+// and the bytes of the request body read by the handler and of the response body.
+// sampleRate is the fraction of the requests logged, from none at 0 to all of them at 1 or more;
+// every latency is fed to tracker regardless. tracker may be nil when only logging is wanted.
+func AccessLogMiddleware(logger *slog.Logger, tracker *P99Tracker, sampleRate float64) func(http.Handler) http.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			body := &countingReader{ReadCloser: r.Body}
+			if r.Body != nil {
+				r.Body = body
+			}
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, r)
+			duration := time.Since(start)
+
+			if tracker != nil {
+				tracker.Observe(duration)
+			}
+			if sampleRate < 1 && rand.Float64() >= sampleRate {
+				return
+			}
+			spanContext := trace.SpanContextFromContext(r.Context())
+			logger.InfoContext(r.Context(), "access",
+				slog.String("trace_id", spanContext.TraceID().String()),
+				slog.String("span_id", spanContext.SpanID().String()),
+				slog.String("request_id", RequestIDFromContext(r.Context())),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status_code", rw.status),
+				slog.Int64("request_bytes", body.n),
+				slog.Int64("response_bytes", rw.bytes),
+				slog.Float64("duration_ms", float64(duration)/float64(time.Millisecond)),
+			)
+		})
+	}
+}
+
+// countingReader counts the bytes read from the wrapped request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// responseWriter records the status code and the body size written by the wrapped handler.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	bytes       int64
+}
+
+func (rw *responseWriter) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestAccessLogMiddlewareLogsTraceID(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/weather/01310100", nil)
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(req.Context(), "request")
+	defer span.End()
+	AccessLogMiddleware(logger, nil, 1)(next).ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+	var entry struct {
+		Msg        string  `json:"msg"`
+		TraceID    string  `json:"trace_id"`
+		SpanID     string  `json:"span_id"`
+		Method     string  `json:"method"`
+		Path       string  `json:"path"`
+		StatusCode int     `json:"status_code"`
+		DurationMs float64 `json:"duration_ms"`
+	}
+	if !assert.NoError(t, json.Unmarshal(logs.Bytes(), &entry)) {
+		return
+	}
+	assert.Equal(t, "access", entry.Msg)
+	assert.Equal(t, span.SpanContext().TraceID().String(), entry.TraceID)
+	assert.Equal(t, span.SpanContext().SpanID().String(), entry.SpanID)
+	assert.Equal(t, http.MethodGet, entry.Method)
+	assert.Equal(t, "/weather/01310100", entry.Path)
+	assert.Equal(t, http.StatusOK, entry.StatusCode)
+	assert.Greater(t, entry.DurationMs, 0.0)
+}
+
+func TestAccessLogMiddlewareLogsSizesAndRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"temp_C": 28.5}`))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/weather-service-a", strings.NewReader(`{"cep": "01310100"}`))
+	req.Header.Set(RequestIDHeader, "request-1")
+	Chain(next, RequestIDMiddleware, AccessLogMiddleware(logger, nil, 1)).ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry struct {
+		RequestID     string `json:"request_id"`
+		StatusCode    int    `json:"status_code"`
+		RequestBytes  int64  `json:"request_bytes"`
+		ResponseBytes int64  `json:"response_bytes"`
+	}
+	if !assert.NoError(t, json.Unmarshal(logs.Bytes(), &entry)) {
+		return
+	}
+	assert.Equal(t, "request-1", entry.RequestID)
+	assert.Equal(t, http.StatusCreated, entry.StatusCode)
+	assert.Equal(t, int64(len(`{"cep": "01310100"}`)), entry.RequestBytes)
+	assert.Equal(t, int64(len(`{"temp_C": 28.5}`)), entry.ResponseBytes)
+}
+
+func TestAccessLogMiddlewareSamples(t *testing.T) {
+	tests := []struct {
+		name       string
+		sampleRate float64
+		logged     int
+	}{
+		{name: "All", sampleRate: 1, logged: 100},
+		{name: "Almost none", sampleRate: 1e-12, logged: 0},
+		{name: "None", sampleRate: 0, logged: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&logs, nil))
+			tracker := NewP99Tracker(DefaultLatencyWindow)
+			handler := AccessLogMiddleware(logger, tracker, tt.sampleRate)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+			for i := 0; i < 100; i++ {
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/weather/01310100", nil))
+			}
+
+			assert.Equal(t, tt.logged, bytes.Count(logs.Bytes(), []byte("\n")))
+			// The latencies of the requests not logged are tracked too.
+			assert.Len(t, tracker.sorted, 100)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// AdminTokenHeader holds the token of the operators calling the admin endpoints.
+const AdminTokenHeader = "X-Admin-Token"
+
+// AuthMiddleware only admits the requests carrying token in the X-Admin-Token header.
+// The other requests are answered 401 with a JSON error body.
+// token must not be empty.
+func AuthMiddleware(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The comparison takes the same time whatever the token sent, so that it can not be guessed byte by byte.
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get(AdminTokenHeader)), []byte(token)) != 1 {
+				writeUnauthorized(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/leoseiji/go-tracing/dto"
+)
+
+// DefaultMaxRequestBodyBytes is the request body limit when MAX_REQUEST_BODY_BYTES is unset.
+const DefaultMaxRequestBodyBytes = 1 << 20
+
+var ErrRequestBodyTooLarge = fmt.Errorf("request body too large")
+
+// BodyLimitMiddleware rejects request bodies larger than maxBytes.
+// Requests declaring a larger Content-Length are answered 413 right away.
+// Other bodies are wrapped with http.MaxBytesReader, so reading past the limit fails
+// with *http.MaxBytesError, which handlers answer with WriteRequestBodyTooLarge.
+func BodyLimitMiddleware(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				WriteRequestBodyTooLarge(w)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WriteRequestBodyTooLarge answers 413 Request Entity Too Large with a JSON error body.
+func WriteRequestBodyTooLarge(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	json.NewEncoder(w).Encode(dto.ErrorResponse{Error: ErrRequestBodyTooLarge.Error()})
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	const maxBytes = 16
+	tests := []struct {
+		name          string
+		size          int
+		unknownLength bool
+		status        int
+	}{
+		{name: "Body at the limit", size: maxBytes, status: http.StatusOK},
+		{name: "Body one byte over the limit", size: maxBytes + 1, status: http.StatusRequestEntityTooLarge},
+		{name: "Body of unknown length at the limit", size: maxBytes, unknownLength: true, status: http.StatusOK},
+		{name: "Body of unknown length one byte over the limit", size: maxBytes + 1, unknownLength: true, status: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					WriteRequestBodyTooLarge(w)
+					return
+				}
+				assert.Len(t, body, tt.size)
+			})
+			req := httptest.NewRequest(http.MethodPost, "/weather-service-a", strings.NewReader(strings.Repeat("a", tt.size)))
+			if tt.unknownLength {
+				req.ContentLength = -1
+			}
+			rr := httptest.NewRecorder()
+			BodyLimitMiddleware(maxBytes)(next).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			if tt.status == http.StatusRequestEntityTooLarge {
+				assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+				assert.JSONEq(t, `{"error": "request body too large"}`, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import "net/http"
+
+// Middleware wraps an http.Handler with cross-cutting behaviour, such as logging or CORS.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps handler with middlewares, applied in order:
+// the first middleware is the outermost one and sees every request first.
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainMiddlewareWrapsHandlerCorrectly(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name+"_pre")
+				next.ServeHTTP(w, r)
+				calls = append(calls, name+"_post")
+			})
+		}
+	}
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "inner")
+	})
+
+	handler := Chain(inner, record("m1"), record("m2"), record("m3"))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/weather-service-b/06233903", nil))
+
+	assert.Equal(t, []string{"m1_pre", "m2_pre", "m3_pre", "inner", "m3_post", "m2_post", "m1_post"}, calls)
+}
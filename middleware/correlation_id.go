@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// CorrelationIDHeader is the request header clients set their own correlation ID in.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// CorrelationIDBaggageKey is the baggage member carrying the client correlation ID across services.
+const CorrelationIDBaggageKey = "correlation.id"
+
+// ClientCorrelationIDMiddleware copies the X-Correlation-ID request header into the OTel baggage,
+// so the baggage propagator forwards it to every downstream service.
+// Requests without the header, or with a value that is not valid baggage, are served unchanged.
+func ClientCorrelationIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationID := r.Header.Get(CorrelationIDHeader)
+		if correlationID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		member, err := baggage.NewMember(CorrelationIDBaggageKey, url.PathEscape(correlationID))
+		if err == nil {
+			var bag baggage.Baggage
+			bag, err = baggage.FromContext(ctx).SetMember(member)
+			if err == nil {
+				ctx = baggage.ContextWithBaggage(ctx, bag)
+			}
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "ignoring correlation ID", slog.String("error", err.Error()))
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	// corsAllowedMethods are the methods browsers may use on the public endpoints.
+	corsAllowedMethods = "GET, POST, OPTIONS"
+	// corsAllowedHeaders are the request headers browsers may send,
+	// including those carrying the trace context and the client correlation ID.
+	corsAllowedHeaders = "Content-Type, " + CorrelationIDHeader + ", traceparent, tracestate, baggage"
+	// corsMaxAge is how long, in seconds, browsers may cache a preflight answer.
+	corsMaxAge = "600"
+)
+
+// internalPaths are served to operators and other services only, never with CORS headers.
+var internalPaths = []string{"/metricz", "/metrics", "/health"}
+
+// CORSMiddleware lets browser applications served from allowedOrigins call the API.
+// An allowed origin of "*" allows any origin.
+// Preflight OPTIONS requests are answered directly: 204 No Content for allowed origins
+// and 403 Forbidden otherwise. Other requests are passed to next, with the CORS headers
+// set only when their origin is allowed.
+func CORSMiddleware(allowedOrigins []string) func(http.Handler) http.Handler {
+	allowAny := slices.Contains(allowedOrigins, "*")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" || slices.Contains(internalPaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed := allowAny || slices.Contains(allowedOrigins, origin)
+			if allowed {
+				if allowAny {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				} else {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+					w.Header().Add("Vary", "Origin")
+				}
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				if !allowed {
+					http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+					return
+				}
+				w.Header().Set("Access-Control-Allow-Methods", corsAllowedMethods)
+				w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+				w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ParseAllowedOrigins splits a comma-separated list of origins, such as CORS_ALLOWED_ORIGINS.
+// An empty list allows any origin.
+func ParseAllowedOrigins(origins string) []string {
+	var allowedOrigins []string
+	for _, origin := range strings.Split(origins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			allowedOrigins = append(allowedOrigins, origin)
+		}
+	}
+	if len(allowedOrigins) == 0 {
+		return []string{"*"}
+	}
+	return allowedOrigins
+}
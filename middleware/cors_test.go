@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSMiddlewarePreflight(t *testing.T) {
+	tests := []struct {
+		name           string
+		allowedOrigins []string
+		origin         string
+		status         int
+		allowOrigin    string
+	}{
+		{name: "Configured origin", allowedOrigins: []string{"https://app.example.com"}, origin: "https://app.example.com", status: http.StatusNoContent, allowOrigin: "https://app.example.com"},
+		{name: "Any origin", allowedOrigins: []string{"*"}, origin: "https://app.example.com", status: http.StatusNoContent, allowOrigin: "*"},
+		{name: "Unknown origin", allowedOrigins: []string{"https://app.example.com"}, origin: "https://evil.example.com", status: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Error("preflight request reached the handler")
+			})
+			req := httptest.NewRequest(http.MethodOptions, "/weather/batch", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			rr := httptest.NewRecorder()
+			CORSMiddleware(tt.allowedOrigins)(next).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			assert.Equal(t, tt.allowOrigin, rr.Header().Get("Access-Control-Allow-Origin"))
+			if tt.status == http.StatusNoContent {
+				assert.Equal(t, "GET, POST, OPTIONS", rr.Header().Get("Access-Control-Allow-Methods"))
+				assert.Contains(t, rr.Header().Get("Access-Control-Allow-Headers"), "Content-Type")
+			}
+		})
+	}
+}
+
+func TestCORSMiddlewareOriginValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		origin      string
+		allowOrigin string
+	}{
+		{name: "Configured origin", path: "/weather-service-a", origin: "https://app.example.com", allowOrigin: "https://app.example.com"},
+		{name: "Unknown origin", path: "/weather-service-a", origin: "https://evil.example.com"},
+		{name: "Same-origin request", path: "/weather-service-a"},
+		{name: "Internal endpoint", path: "/metricz", origin: "https://app.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var served bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				served = true
+			})
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rr := httptest.NewRecorder()
+			CORSMiddleware([]string{"https://app.example.com"})(next).ServeHTTP(rr, req)
+
+			assert.True(t, served)
+			assert.Equal(t, tt.allowOrigin, rr.Header().Get("Access-Control-Allow-Origin"))
+		})
+	}
+}
+
+func TestCORSMiddlewareAllowsConfiguredOrigin(t *testing.T) {
+	cors := CORSMiddleware([]string{"https://app.example.com"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tests := []struct {
+		name        string
+		method      string
+		origin      string
+		status      int
+		allowOrigin string
+	}{
+		{name: "Configured origin", method: http.MethodGet, origin: "https://app.example.com", status: http.StatusOK, allowOrigin: "https://app.example.com"},
+		{name: "Other origin", method: http.MethodGet, origin: "https://evil.com", status: http.StatusOK},
+		{name: "Preflight", method: http.MethodOptions, origin: "https://app.example.com", status: http.StatusNoContent, allowOrigin: "https://app.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/weather-service-b/06233903", nil)
+			req.Header.Set("Origin", tt.origin)
+			if tt.method == http.MethodOptions {
+				req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			}
+			rr := httptest.NewRecorder()
+			cors.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			assert.Equal(t, tt.allowOrigin, rr.Header().Get("Access-Control-Allow-Origin"))
+			if tt.allowOrigin == "" {
+				assert.NotContains(t, rr.Header(), "Access-Control-Allow-Origin")
+			}
+		})
+	}
+}
+
+func TestParseAllowedOrigins(t *testing.T) {
+	assert.Equal(t, []string{"*"}, ParseAllowedOrigins(""))
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, ParseAllowedOrigins("https://a.example.com, https://b.example.com,"))
+}
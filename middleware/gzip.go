@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultGzipMinSize is the size from which responses are compressed when GZIP_MIN_SIZE_BYTES is unset.
+// Smaller responses would hardly shrink and are not worth the CPU.
+const DefaultGzipMinSize = 1024
+
+// GzipMiddleware compresses the response bodies of at least minSize bytes with gzip,
+// for the clients accepting it. Responses already encoded by the handler are sent unchanged.
+func GzipMiddleware(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			gw := &gzipResponseWriter{ResponseWriter: w, minSize: minSize, status: http.StatusOK}
+			next.ServeHTTP(gw, r)
+			gw.Close()
+		})
+	}
+}
+
+// acceptsGzip reports whether the Accept-Encoding header acceptEncoding lists gzip without q=0.
+func acceptsGzip(acceptEncoding string) bool {
+	for _, coding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(coding, ";")
+		name = strings.TrimSpace(name)
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				q, _ = strconv.ParseFloat(value, 64)
+			}
+		}
+		return q > 0
+	}
+	return false
+}
+
+// gzipResponseWriter holds the response back until minSize bytes are written,
+// to decide whether to compress it, and then writes it compressed.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	minSize     int
+	status      int
+	wroteHeader bool
+	buf         bytes.Buffer
+	gz          *gzip.Writer
+	passthrough bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	switch {
+	case w.gz != nil:
+		return w.gz.Write(b)
+	case w.passthrough:
+		return w.ResponseWriter.Write(b)
+	}
+	w.buf.Write(b)
+	if w.buf.Len() < w.minSize {
+		return len(b), nil
+	}
+	if err := w.start(w.Header().Get("Content-Encoding") == ""); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// start sends the status and the bytes held back, compressed or not.
+func (w *gzipResponseWriter) start(compress bool) error {
+	if compress {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+		w.ResponseWriter.WriteHeader(w.status)
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+		_, err := w.gz.Write(w.buf.Bytes())
+		return err
+	}
+	w.passthrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	return err
+}
+
+// Close sends the responses smaller than minSize unchanged and ends the compressed ones.
+func (w *gzipResponseWriter) Close() error {
+	switch {
+	case w.gz != nil:
+		return w.gz.Close()
+	case w.passthrough:
+		return nil
+	}
+	return w.start(false)
+}
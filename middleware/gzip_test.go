@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGzipMiddlewareCompressesLargeResponses(t *testing.T) {
+	body := `{"condition": "` + strings.Repeat("a", 1000-len(`{"condition": ""}`)) + `"}`
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		body           string
+		compressed     bool
+	}{
+		{name: "Large response accepting gzip", acceptEncoding: "gzip, deflate", body: body, compressed: true},
+		{name: "Large response without Accept-Encoding", body: body},
+		{name: "Large response refusing gzip", acceptEncoding: "gzip;q=0, deflate", body: body},
+		{name: "Small response accepting gzip", acceptEncoding: "gzip", body: `{"city": "Osasco"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				io.WriteString(w, tt.body)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/v1/weather-service-b/06233903", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rr := httptest.NewRecorder()
+			GzipMiddleware(100)(next).ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+			if !tt.compressed {
+				assert.Empty(t, rr.Header().Get("Content-Encoding"))
+				assert.Equal(t, tt.body, rr.Body.String())
+				return
+			}
+			assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+			assert.Less(t, rr.Body.Len(), len(tt.body))
+			gz, err := gzip.NewReader(rr.Body)
+			if !assert.NoError(t, err) {
+				return
+			}
+			decompressed, err := io.ReadAll(gz)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.body, string(decompressed))
+		})
+	}
+}
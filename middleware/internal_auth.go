@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/leoseiji/go-tracing/dto"
+)
+
+const (
+	// InternalTimestampHeader holds the Unix time in seconds a request between the services was signed at.
+	InternalTimestampHeader = "X-Internal-Timestamp"
+	// InternalSignatureHeader holds the hex HMAC-SHA256 signature of a request between the services.
+	InternalSignatureHeader = "X-Internal-Signature"
+)
+
+// DefaultSignatureSkew is the default skew tolerated between the signing time of a request and its verification.
+const DefaultSignatureSkew = 30 * time.Second
+
+var ErrUnauthorized = fmt.Errorf("unauthorized")
+
+// SignRequest signs req with secret at now, for InternalAuthMiddleware to verify.
+func SignRequest(req *http.Request, secret []byte, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set(InternalTimestampHeader, timestamp)
+	req.Header.Set(InternalSignatureHeader, signature(secret, req.Method, req.URL.EscapedPath(), timestamp))
+}
+
+// InternalAuthMiddleware only admits the requests signed with secret by SignRequest
+// less than skew away from now, so that a captured request can not be replayed later.
+// The other requests are answered 401 with a JSON error body.
+func InternalAuthMiddleware(secret []byte, skew time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !verifySignature(r, secret, skew, time.Now()) {
+				writeUnauthorized(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// verifySignature reports whether r is signed with secret less than skew away from now.
+func verifySignature(r *http.Request, secret []byte, skew time.Duration, now time.Time) bool {
+	return verify(secret, skew, now, r.Method, r.URL.EscapedPath(),
+		r.Header.Get(InternalTimestampHeader), r.Header.Get(InternalSignatureHeader))
+}
+
+// verify reports whether sig is the signature of method and path with secret at timestamp,
+// and timestamp is less than skew away from now.
+func verify(secret []byte, skew time.Duration, now time.Time, method, path, timestamp, sig string) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > skew || age < -skew {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	want, _ := hex.DecodeString(signature(secret, method, path, timestamp))
+	return hmac.Equal(got, want)
+}
+
+// signature is the hex HMAC-SHA256 of method, path and timestamp with secret.
+func signature(secret []byte, method, path, timestamp string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(method + "\n" + path + "\n" + timestamp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// writeUnauthorized answers 401 Unauthorized with a JSON error body.
+func writeUnauthorized(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(dto.ErrorResponse{Error: ErrUnauthorized.Error()})
+}
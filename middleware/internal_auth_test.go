@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInternalAuthMiddleware(t *testing.T) {
+	secret := []byte("secret")
+	authenticated := InternalAuthMiddleware(secret, DefaultSignatureSkew)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name   string
+		sign   func(req *http.Request)
+		status int
+	}{
+		{
+			name:   "Valid signature",
+			sign:   func(req *http.Request) { SignRequest(req, secret, time.Now()) },
+			status: http.StatusOK,
+		},
+		{
+			name:   "Missing signature",
+			sign:   func(req *http.Request) {},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Expired signature",
+			sign:   func(req *http.Request) { SignRequest(req, secret, time.Now().Add(-time.Minute)) },
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Signature from the future",
+			sign:   func(req *http.Request) { SignRequest(req, secret, time.Now().Add(time.Minute)) },
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Wrong secret",
+			sign:   func(req *http.Request) { SignRequest(req, []byte("guess"), time.Now()) },
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "Tampered path",
+			sign: func(req *http.Request) {
+				SignRequest(req, secret, time.Now())
+				req.URL.Path = "/v1/weather-service-b/01001000"
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "Tampered timestamp",
+			sign: func(req *http.Request) {
+				SignRequest(req, secret, time.Now().Add(-time.Minute))
+				req.Header.Set(InternalTimestampHeader, strconv.FormatInt(time.Now().Unix(), 10))
+			},
+			status: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/weather-service-b/06233903", nil)
+			tt.sign(req)
+			rr := httptest.NewRecorder()
+			authenticated.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			if tt.status == http.StatusUnauthorized {
+				assert.JSONEq(t, `{"error": "unauthorized"}`, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyWindow is the number of most recent requests kept by a P99Tracker.
+const DefaultLatencyWindow = 1000
+
+// P99Tracker keeps a sliding window of the most recent request latencies
+// and computes percentiles over it in-process.
+// Latencies are kept both in arrival order, to evict the oldest one,
+// and sorted, so that the eviction and insertion points are found with a
+// binary search and percentiles are read directly by rank.
+type P99Tracker struct {
+	mu     sync.Mutex
+	window []float64
+	next   int
+	full   bool
+	sorted []float64
+}
+
+// NewP99Tracker creates a P99Tracker holding the last size latencies.
+// A non-positive size falls back to DefaultLatencyWindow.
+func NewP99Tracker(size int) *P99Tracker {
+	if size <= 0 {
+		size = DefaultLatencyWindow
+	}
+	return &P99Tracker{
+		window: make([]float64, size),
+		sorted: make([]float64, 0, size),
+	}
+}
+
+// Observe adds a request latency to the window, evicting the oldest one when the window is full.
+func (t *P99Tracker) Observe(d time.Duration) {
+	ms := float64(d) / float64(time.Millisecond)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.full {
+		oldest := t.window[t.next]
+		i := sort.SearchFloat64s(t.sorted, oldest)
+		t.sorted = append(t.sorted[:i], t.sorted[i+1:]...)
+	}
+	t.window[t.next] = ms
+	t.next = (t.next + 1) % len(t.window)
+	if t.next == 0 {
+		t.full = true
+	}
+
+	i := sort.SearchFloat64s(t.sorted, ms)
+	t.sorted = append(t.sorted, 0)
+	copy(t.sorted[i+1:], t.sorted[i:])
+	t.sorted[i] = ms
+}
+
+// Percentile returns the p-th percentile (0-100) of the window in milliseconds,
+// using the nearest-rank method. It returns 0 when nothing was observed yet.
+func (t *P99Tracker) Percentile(p float64) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := len(t.sorted)
+	if n == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(n)))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > n {
+		rank = n
+	}
+	return t.sorted[rank-1]
+}
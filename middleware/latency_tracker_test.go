@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestP99Tracker(t *testing.T) {
+	t.Run("Uniform distribution", func(t *testing.T) {
+		tracker := NewP99Tracker(DefaultLatencyWindow)
+		for _, i := range rand.New(rand.NewSource(1)).Perm(1000) {
+			tracker.Observe(time.Duration(i+1) * time.Millisecond)
+		}
+
+		assert.InDelta(t, 990, tracker.Percentile(99), 5)
+	})
+
+	t.Run("Bimodal distribution", func(t *testing.T) {
+		tracker := NewP99Tracker(DefaultLatencyWindow)
+		for i := 0; i < 1000; i++ {
+			latency := 10 * time.Millisecond
+			if i%10 == 0 {
+				latency = 500 * time.Millisecond
+			}
+			tracker.Observe(latency)
+		}
+
+		assert.InDelta(t, 10, tracker.Percentile(90), 5)
+		assert.InDelta(t, 500, tracker.Percentile(99), 5)
+	})
+
+	t.Run("Oldest latencies are evicted", func(t *testing.T) {
+		tracker := NewP99Tracker(10)
+		for i := 0; i < 10; i++ {
+			tracker.Observe(time.Second)
+		}
+		for i := 0; i < 10; i++ {
+			tracker.Observe(time.Millisecond)
+		}
+
+		assert.Equal(t, 1.0, tracker.Percentile(99))
+	})
+}
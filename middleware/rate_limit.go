@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leoseiji/go-tracing/clock"
+	"github.com/leoseiji/go-tracing/dto"
+	"golang.org/x/time/rate"
+)
+
+var ErrTooManyRequests = fmt.Errorf("too many requests")
+
+// adminPathPrefix prefixes the paths of the admin endpoints.
+const adminPathPrefix = "/admin/"
+
+// RateLimiter admits a number of requests per second, with bursts of up to a number of requests,
+// shared by all the clients. The rate can be changed while it serves.
+type RateLimiter struct {
+	limiter *rate.Limiter
+	clock   clock.Clock
+	// secret and skew verify the signed requests, which are not limited. See ExemptSigned.
+	secret []byte
+	skew   time.Duration
+}
+
+// NewRateLimiter admits rps requests per second, with bursts of up to burst requests.
+// The rate is not limited when rps is not positive. burst must be at least 1.
+// The rate is measured on the time of clk, which defaults to clock.Real.
+func NewRateLimiter(rps float64, burst int, clk clock.Clock) *RateLimiter {
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	return &RateLimiter{limiter: rate.NewLimiter(limit(rps), burst), clock: clk}
+}
+
+// limit is the rate.Limit of rps requests per second, infinite when rps is not positive.
+func limit(rps float64) rate.Limit {
+	if rps <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(rps)
+}
+
+// RPS returns the requests admitted per second, 0 when the rate is not limited.
+func (l *RateLimiter) RPS() float64 {
+	if l.limiter.Limit() == rate.Inf {
+		return 0
+	}
+	return float64(l.limiter.Limit())
+}
+
+// SetRPS changes the requests admitted per second from now on. The rate is not limited when rps is not positive.
+func (l *RateLimiter) SetRPS(rps float64) {
+	l.limiter.SetLimitAt(l.clock.Now(), limit(rps))
+}
+
+// ExemptSigned lets the requests signed with secret by SignRequest less than skew away through without limiting them,
+// so that the calls of service A to service B on the same server do not use up the rate of its clients.
+// It must be called before serving.
+func (l *RateLimiter) ExemptSigned(secret []byte, skew time.Duration) {
+	l.secret, l.skew = secret, skew
+}
+
+// Middleware answers 429 to the requests over the limit, with the seconds until the next one is admitted in Retry-After.
+// The internal and admin endpoints are not limited, so that probes and scrapes keep working under load
+// and operators can still change the rate, nor are the signed requests, see ExemptSigned.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(internalPaths, r.URL.Path) || strings.HasPrefix(r.URL.Path, adminPathPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(l.secret) > 0 && verifySignature(r, l.secret, l.skew, time.Now()) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		now := l.clock.Now()
+		reservation := l.limiter.ReserveN(now, 1)
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			// The request is rejected, not delayed, so give its token back.
+			reservation.CancelAt(now)
+			if reservation.OK() {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			}
+			writeTooManyRequests(w)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RateLimitMiddleware admits rps requests per second, with bursts of up to burst requests,
+// shared by all the clients. burst must be at least 1. See RateLimiter.Middleware.
+func RateLimitMiddleware(rps float64, burst int) func(http.Handler) http.Handler {
+	return NewRateLimiter(rps, burst, nil).Middleware
+}
+
+// writeTooManyRequests answers 429 Too Many Requests with a JSON error body.
+func writeTooManyRequests(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(dto.ErrorResponse{Error: ErrTooManyRequests.Error()})
+}
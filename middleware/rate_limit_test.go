@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitMiddlewareReturns429(t *testing.T) {
+	rateLimited := RateLimitMiddleware(1, 1)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		rateLimited.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/weather-service-b/06233903").Code)
+	for i := 0; i < 2; i++ {
+		rr := serve("/weather-service-b/06233903")
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+		assert.JSONEq(t, `{"error": "too many requests"}`, rr.Body.String())
+	}
+	assert.Equal(t, http.StatusOK, serve("/metrics").Code)
+
+	// The bucket refills at 1 token per second.
+	time.Sleep(time.Second)
+	assert.Equal(t, http.StatusOK, serve("/weather-service-b/06233903").Code)
+}
+
+func TestRateLimiterExemptsSignedRequests(t *testing.T) {
+	secret := []byte("secret")
+	rateLimiter := NewRateLimiter(1, 1, nil)
+	rateLimiter.ExemptSigned(secret, DefaultSignatureSkew)
+	rateLimited := rateLimiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve := func(secret []byte) int {
+		req := httptest.NewRequest(http.MethodGet, "/weather-service-b/06233903", nil)
+		if secret != nil {
+			SignRequest(req, secret, time.Now())
+		}
+		rr := httptest.NewRecorder()
+		rateLimited.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(nil))
+	assert.Equal(t, http.StatusTooManyRequests, serve(nil))
+	assert.Equal(t, http.StatusOK, serve(secret))
+	assert.Equal(t, http.StatusOK, serve(secret))
+	assert.Equal(t, http.StatusTooManyRequests, serve([]byte("guess")))
+}
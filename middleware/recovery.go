@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/leoseiji/go-tracing/dto"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrInternalServerError is answered for the panics recovered, and by the handlers for unexpected errors.
+var ErrInternalServerError = fmt.Errorf("internal server error")
+
+// RecoveryMiddleware answers 500 to the requests whose handler panics, instead of dropping the connection.
+// The panic is logged with its stack and marks the request span as failed.
+// http.ErrAbortHandler is panicked again, to abort the response as intended.
+func RecoveryMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				message := fmt.Sprint(recovered)
+				span := trace.SpanFromContext(r.Context())
+				span.SetStatus(codes.Error, message)
+				logger.ErrorContext(r.Context(), "panic serving request",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("panic", message),
+					slog.String("stack", string(debug.Stack())),
+				)
+				// The status can not be changed once the handler has written it.
+				if !rw.wroteHeader {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusInternalServerError)
+					json.NewEncoder(w).Encode(dto.ErrorResponse{Error: ErrInternalServerError.Error()})
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
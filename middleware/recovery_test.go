@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRecoveryMiddlewareCatchesPanic(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("test panic")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/weather-service-b/06233903", nil)
+	ctx, span := tracer.Start(req.Context(), "request")
+	rr := httptest.NewRecorder()
+	assert.NotPanics(t, func() { RecoveryMiddleware(logger)(next).ServeHTTP(rr, req.WithContext(ctx)) })
+	span.End()
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.JSONEq(t, `{"error": "internal server error"}`, rr.Body.String())
+	assert.Contains(t, logs.String(), "panic serving request")
+	assert.Contains(t, logs.String(), `panic="test panic"`)
+	if spans := recorder.Ended(); assert.Len(t, spans, 1) {
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Equal(t, "test panic", spans[0].Status().Description)
+	}
+}
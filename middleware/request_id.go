@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is the header identifying a request, in both the request and its response.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the span attribute holding the request ID.
+const requestIDKey = attribute.Key("request.id")
+
+type requestIDContextKey struct{}
+
+// RequestIDMiddleware identifies every request by the X-Request-ID header set by the client or a proxy,
+// or by a new UUID when the request has none, and echoes it in the response.
+// The ID is recorded on the request span and can be read by the handlers with RequestIDFromContext.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := r.Context()
+		trace.SpanFromContext(ctx).SetAttributes(requestIDKey.String(requestID))
+		next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, requestIDContextKey{}, requestID)))
+	})
+}
+
+// RequestIDFromContext returns the ID of the request served with ctx, set by RequestIDMiddleware.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDMiddlewareGeneratesUniqueIDs(t *testing.T) {
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, w.Header().Get(RequestIDHeader), RequestIDFromContext(r.Context()))
+	}))
+
+	requestIDs := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/weather-service-b/06233903", nil))
+
+		requestID := rr.Header().Get(RequestIDHeader)
+		_, err := uuid.Parse(requestID)
+		assert.NoError(t, err)
+		requestIDs[requestID] = true
+	}
+	assert.Len(t, requestIDs, 100)
+}
+
+func TestRequestIDMiddlewareEchoesRequestID(t *testing.T) {
+	var requestID string
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = RequestIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/weather-service-b/06233903", nil)
+	req.Header.Set(RequestIDHeader, "my-id")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, "my-id", rr.Header().Get(RequestIDHeader))
+	assert.Equal(t, "my-id", requestID)
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeadersMiddlewareAddsHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		next   http.HandlerFunc
+		status int
+	}{
+		{name: "Success", next: func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`{}`)) }, status: http.StatusOK},
+		{name: "Error", next: func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "can not find zipcode", http.StatusNotFound)
+		}, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			SecurityHeadersMiddleware(tt.next).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/weather-service-b/06233903", nil))
+
+			assert.Equal(t, tt.status, rr.Code)
+			assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, "DENY", rr.Header().Get("X-Frame-Options"))
+			assert.Equal(t, "default-src 'none'", rr.Header().Get("Content-Security-Policy"))
+		})
+	}
+}
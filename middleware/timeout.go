@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultRequestTimeout bounds serving a request when REQUEST_TIMEOUT_MS is unset.
+// It is shorter than the write timeout of the server so that clients get the 503.
+const DefaultRequestTimeout = 9 * time.Second
+
+// TimeoutMiddleware answers 503 Service Unavailable to the requests not served within timeout.
+// The context of the request is canceled then, so handlers must return once it is done
+// for their goroutine to end; what they write afterwards is discarded.
+func TimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.TimeoutHandler(next, timeout, "service unavailable\n")
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+)
+
+func TestTimeoutMiddlewareReturns503(t *testing.T) {
+	ignoreCurrent := goleak.IgnoreCurrent()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+			w.Write([]byte("too late"))
+		case <-r.Context().Done():
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/weather-service-b/06233903", nil)
+	rr := httptest.NewRecorder()
+	start := time.Now()
+	TimeoutMiddleware(50*time.Millisecond)(next).ServeHTTP(rr, req)
+
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "service unavailable\n", rr.Body.String())
+	// The handler returns once its context is canceled.
+	goleak.VerifyNone(t, ignoreCurrent)
+}
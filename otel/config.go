@@ -0,0 +1,200 @@
+package otel
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Version is the version of the service, set at build time with
+//
+//	go build -ldflags "-X github.com/leoseiji/go-tracing/otel.Version=v1.2.3"
+var Version = "dev"
+
+// Commit and BuiltAt identify the build of the service, set at build time with
+//
+//	go build -ldflags "-X github.com/leoseiji/go-tracing/otel.Commit=$(git rev-parse HEAD) -X github.com/leoseiji/go-tracing/otel.BuiltAt=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// BuiltAt is an RFC3339 time.
+var (
+	Commit  = "unknown"
+	BuiltAt = ""
+)
+
+const (
+	// DefaultOTLPEndpoint is the OTLP/HTTP collector used when OTEL_EXPORTER_OTLP_ENDPOINT is unset.
+	DefaultOTLPEndpoint = "http://localhost:4318"
+	// DefaultServiceName is the service name used when OTEL_SERVICE_NAME is unset.
+	DefaultServiceName = "WeatherService"
+	// DefaultEnvironment is the deployment environment used when ENVIRONMENT is unset.
+	DefaultEnvironment = "dev"
+
+	// ExporterOTLP, ExporterStdout and ExporterNoop are the values accepted by OTEL_EXPORTER.
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterNoop   = "noop"
+
+	// DefaultPropagators are the propagators used when OTEL_PROPAGATORS is unset:
+	// W3C TraceContext and Baggage, plus B3 multi-header for Zipkin-style callers.
+	DefaultPropagators = "tracecontext,baggage,b3multi"
+
+	// otlpTracesPath is appended to the OTLP endpoint for traces, as defined by the OTel specification.
+	otlpTracesPath = "/v1/traces"
+)
+
+// Config holds the settings of the OpenTelemetry pipeline.
+type Config struct {
+	// OTLPEndpoint is the base URL of the OTLP/HTTP collector, without the signal path.
+	OTLPEndpoint string
+	ServiceName  string
+	// ServiceVersion is reported as service.version. Defaults to Version.
+	ServiceVersion string
+	// Environment is reported as deployment.environment, e.g. production, staging or dev.
+	Environment string
+	// InstanceID is reported as service.instance.id to tell the replicas of the service apart.
+	InstanceID string
+	// Exporter selects where spans go: ExporterOTLP, ExporterStdout or ExporterNoop.
+	Exporter string
+	// Sampler decides which traces are recorded. Defaults to always sampling.
+	Sampler trace.Sampler
+	// Propagator reads and writes the trace context of requests. Defaults to DefaultPropagators.
+	Propagator propagation.TextMapPropagator
+}
+
+// LoadConfig reads the OpenTelemetry settings from the environment variables defined by the OTel specification:
+//
+//	OTEL_EXPORTER_OTLP_ENDPOINT  base URL of the OTLP/HTTP collector (default http://localhost:4318)
+//	OTEL_SERVICE_NAME            service name shown in the tracing backend (default WeatherService)
+//	OTEL_EXPORTER                trace exporter (default otlp):
+//	                               otlp    export to the OTLP/HTTP collector
+//	                               stdout  print spans as JSON to stderr, for local development
+//	                               noop    record nothing, useful in tests
+//	OTEL_TRACES_SAMPLER          always_on (default), always_off, traceidratio or parentbased_traceidratio
+//	OTEL_TRACES_SAMPLER_ARG      sampling ratio between 0 and 1 for the ratio samplers (default 1)
+//	OTEL_PROPAGATORS             comma-separated tracecontext, baggage, b3 (single header), b3multi
+//	                             or none (default tracecontext,baggage,b3multi)
+//
+// and the deployment environment from ENVIRONMENT (default dev).
+// The service instance ID is the hostname, or a random UUID when it is unavailable.
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		OTLPEndpoint:   os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		ServiceName:    os.Getenv("OTEL_SERVICE_NAME"),
+		ServiceVersion: Version,
+		Environment:    os.Getenv("ENVIRONMENT"),
+		InstanceID:     instanceID(),
+		Exporter:       os.Getenv("OTEL_EXPORTER"),
+	}
+	if cfg.OTLPEndpoint == "" {
+		cfg.OTLPEndpoint = DefaultOTLPEndpoint
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = DefaultServiceName
+	}
+	if cfg.Environment == "" {
+		cfg.Environment = DefaultEnvironment
+	}
+	switch cfg.Exporter {
+	case "":
+		cfg.Exporter = ExporterOTLP
+	case ExporterOTLP, ExporterStdout, ExporterNoop:
+	default:
+		return Config{}, fmt.Errorf("invalid OTEL_EXPORTER %q: expected %s, %s or %s", cfg.Exporter, ExporterOTLP, ExporterStdout, ExporterNoop)
+	}
+
+	sampler, err := newSampler(os.Getenv("OTEL_TRACES_SAMPLER"), os.Getenv("OTEL_TRACES_SAMPLER_ARG"))
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Sampler = sampler
+
+	propagators := os.Getenv("OTEL_PROPAGATORS")
+	if propagators == "" {
+		propagators = DefaultPropagators
+	}
+	propagator, err := newPropagator(propagators)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Propagator = propagator
+
+	if _, err := cfg.tracesEndpointURL(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// instanceID identifies this process among the replicas of the service.
+func instanceID() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return uuid.NewString()
+}
+
+// newSampler builds the sampler named by OTEL_TRACES_SAMPLER, as defined by the OTel specification.
+func newSampler(name, arg string) (trace.Sampler, error) {
+	switch name {
+	case "", "always_on":
+		return trace.AlwaysSample(), nil
+	case "always_off":
+		return trace.NeverSample(), nil
+	case "traceidratio", "parentbased_traceidratio":
+		ratio := 1.0
+		if arg != "" {
+			var err error
+			ratio, err = strconv.ParseFloat(arg, 64)
+			if err != nil || ratio < 0 || ratio > 1 {
+				return nil, fmt.Errorf("invalid OTEL_TRACES_SAMPLER_ARG %q: expected a number between 0 and 1", arg)
+			}
+		}
+		if name == "traceidratio" {
+			return trace.TraceIDRatioBased(ratio), nil
+		}
+		return trace.ParentBased(trace.TraceIDRatioBased(ratio)), nil
+	default:
+		return nil, fmt.Errorf("invalid OTEL_TRACES_SAMPLER %q: expected always_on, always_off, traceidratio or parentbased_traceidratio", name)
+	}
+}
+
+// newPropagator builds the composite propagator listed by OTEL_PROPAGATORS, as defined by the OTel specification.
+// Extraction accepts any of the listed formats and injection writes all of them.
+func newPropagator(names string) (propagation.TextMapPropagator, error) {
+	var propagators []propagation.TextMapPropagator
+	for _, name := range strings.Split(names, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "b3":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case "b3multi":
+			propagators = append(propagators, b3.New())
+		case "none":
+		default:
+			return nil, fmt.Errorf("invalid OTEL_PROPAGATORS %q: unknown propagator %q, expected tracecontext, baggage, b3, b3multi or none", names, name)
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
+
+// tracesEndpointURL returns the URL traces are exported to.
+func (c Config) tracesEndpointURL() (string, error) {
+	u, err := url.Parse(c.OTLPEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid OTEL_EXPORTER_OTLP_ENDPOINT %q: %w", c.OTLPEndpoint, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid OTEL_EXPORTER_OTLP_ENDPOINT %q: expected an http or https URL such as %s", c.OTLPEndpoint, DefaultOTLPEndpoint)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + otlpTracesPath
+	return u.String(), nil
+}
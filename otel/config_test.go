@@ -0,0 +1,209 @@
+package otel
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name              string
+		endpoint          string
+		serviceName       string
+		tracesEndpointURL string
+		wantErr           bool
+	}{
+		{name: "Defaults", tracesEndpointURL: "http://localhost:4318/v1/traces"},
+		{name: "Custom endpoint", endpoint: "https://collector:4318", serviceName: "service-b", tracesEndpointURL: "https://collector:4318/v1/traces"},
+		{name: "Endpoint with path", endpoint: "http://collector:4318/otlp/", tracesEndpointURL: "http://collector:4318/otlp/v1/traces"},
+		{name: "Endpoint without scheme", endpoint: "collector:4318", wantErr: true},
+		{name: "Unparseable endpoint", endpoint: "http://collector:port", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", tt.endpoint)
+			t.Setenv("OTEL_SERVICE_NAME", tt.serviceName)
+
+			cfg, err := LoadConfig()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			tracesEndpointURL, _ := cfg.tracesEndpointURL()
+			assert.Equal(t, tt.tracesEndpointURL, tracesEndpointURL)
+			if tt.serviceName == "" {
+				assert.Equal(t, DefaultServiceName, cfg.ServiceName)
+			} else {
+				assert.Equal(t, tt.serviceName, cfg.ServiceName)
+			}
+		})
+	}
+}
+
+func TestLoadConfigExporter(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter string
+		want     string
+		wantErr  bool
+	}{
+		{name: "Default", want: ExporterOTLP},
+		{name: "OTLP", exporter: "otlp", want: ExporterOTLP},
+		{name: "Stdout", exporter: "stdout", want: ExporterStdout},
+		{name: "Noop", exporter: "noop", want: ExporterNoop},
+		{name: "Unknown", exporter: "jaeger", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_EXPORTER", tt.exporter)
+
+			cfg, err := LoadConfig()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, cfg.Exporter)
+		})
+	}
+}
+
+func TestLoadConfigSampler(t *testing.T) {
+	tests := []struct {
+		name        string
+		sampler     string
+		arg         string
+		description string
+		wantErr     bool
+	}{
+		{name: "Default", description: "AlwaysOnSampler"},
+		{name: "Always on", sampler: "always_on", description: "AlwaysOnSampler"},
+		{name: "Always off", sampler: "always_off", description: "AlwaysOffSampler"},
+		{name: "Ratio", sampler: "traceidratio", arg: "0.25", description: "TraceIDRatioBased{0.25}"},
+		{name: "Ratio without argument", sampler: "traceidratio", description: "AlwaysOnSampler"},
+		{
+			name:        "Parent based ratio",
+			sampler:     "parentbased_traceidratio",
+			arg:         "0.5",
+			description: "ParentBased{root:TraceIDRatioBased{0.5},remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}",
+		},
+		{name: "Ratio above 1", sampler: "traceidratio", arg: "1.5", wantErr: true},
+		{name: "Ratio not a number", sampler: "traceidratio", arg: "half", wantErr: true},
+		{name: "Unknown sampler", sampler: "sometimes", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_SAMPLER", tt.sampler)
+			t.Setenv("OTEL_TRACES_SAMPLER_ARG", tt.arg)
+
+			cfg, err := LoadConfig()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.description, cfg.Sampler.Description())
+			}
+		})
+	}
+}
+
+func TestAlwaysOffSamplerRecordsNothing(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER", ExporterStdout)
+	t.Setenv("OTEL_TRACES_SAMPLER", "always_off")
+	cfg, err := LoadConfig()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tracerProvider, err := newTraceProvider(context.Background(), cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tracerProvider.Shutdown(context.Background())
+	_, span := tracerProvider.Tracer("test").Start(context.Background(), "test")
+	defer span.End()
+
+	assert.False(t, span.IsRecording())
+}
+
+func TestResourceAttributes(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "staging")
+	cfg, err := LoadConfig()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	attrs := map[attribute.Key]string{}
+	for _, kv := range newResource(cfg).Attributes() {
+		attrs[kv.Key] = kv.Value.AsString()
+	}
+
+	assert.Equal(t, DefaultServiceName, attrs[semconv.ServiceNameKey])
+	assert.Equal(t, Version, attrs[semconv.ServiceVersionKey])
+	assert.Equal(t, "staging", attrs[semconv.DeploymentEnvironmentKey])
+	assert.NotEmpty(t, attrs[semconv.ServiceInstanceIDKey])
+}
+
+func TestLoadConfigPropagators(t *testing.T) {
+	const (
+		traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanID = "00f067aa0ba902b7"
+	)
+	tests := []struct {
+		name        string
+		propagators string
+		headers     map[string]string
+		wantParent  bool
+		wantErr     bool
+	}{
+		{name: "Default with traceparent", headers: map[string]string{"traceparent": "00-" + traceID + "-" + parentSpanID + "-01"}, wantParent: true},
+		{name: "Default with B3 multi-header", headers: map[string]string{"X-B3-TraceId": traceID, "X-B3-SpanId": parentSpanID, "X-B3-Sampled": "1"}, wantParent: true},
+		{name: "B3 single header", propagators: "b3", headers: map[string]string{"b3": traceID + "-" + parentSpanID + "-1"}, wantParent: true},
+		{name: "TraceContext ignores B3", propagators: "tracecontext", headers: map[string]string{"X-B3-TraceId": traceID, "X-B3-SpanId": parentSpanID}},
+		{name: "None", propagators: "none", headers: map[string]string{"traceparent": "00-" + traceID + "-" + parentSpanID + "-01"}},
+		{name: "Unknown", propagators: "tracecontext,jaeger", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_PROPAGATORS", tt.propagators)
+			cfg, err := LoadConfig()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			header := http.Header{}
+			for key, value := range tt.headers {
+				header.Set(key, value)
+			}
+			ctx := cfg.Propagator.Extract(context.Background(), propagation.HeaderCarrier(header))
+			recorder := tracetest.NewSpanRecorder()
+			tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			_, span := tracerProvider.Tracer("test").Start(ctx, "test")
+			span.End()
+
+			parent := recorder.Ended()[0].Parent()
+			if tt.wantParent {
+				assert.Equal(t, traceID, parent.TraceID().String())
+				assert.Equal(t, parentSpanID, parent.SpanID().String())
+			} else {
+				assert.False(t, parent.IsValid())
+			}
+		})
+	}
+}
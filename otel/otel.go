@@ -3,25 +3,31 @@ package otel
 import (
 	"context"
 	"errors"
+	"os"
 	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
-	"go.opentelemetry.io/otel/exporters/zipkin"
 	"go.opentelemetry.io/otel/log/global"
-	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+// DefaultShutdownTimeout bounds the flush of the buffered spans and metrics on shutdown.
+const DefaultShutdownTimeout = 5 * time.Second
+
 // setupOTelSDK bootstraps the OpenTelemetry pipeline.
 // If it does not return an error, make sure to call shutdown for proper cleanup.
-func SetupOTelSDK(ctx context.Context) (shutdown func(context.Context) error, err error) {
+// shutdown flushes the spans and metrics not exported yet, until its ctx is done.
+func SetupOTelSDK(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
 	var shutdownFuncs []func(context.Context) error
 
 	// shutdown calls cleanup functions registered via shutdownFuncs.
@@ -42,32 +48,29 @@ func SetupOTelSDK(ctx context.Context) (shutdown func(context.Context) error, er
 	}
 
 	// Set up propagator.
-	prop := newPropagator()
-	otel.SetTextMapPropagator(prop)
-
-	exporter, err := zipkin.New(
-		"http://zipkin:9411/api/v2/spans",
-	)
-	if err != nil {
-		handleErr(err)
-		return
+	if cfg.Propagator == nil {
+		cfg.Propagator, err = newPropagator(DefaultPropagators)
+		if err != nil {
+			handleErr(err)
+			return
+		}
 	}
+	otel.SetTextMapPropagator(cfg.Propagator)
 
 	// Set up trace provider.
-	tracerProvider := trace.NewTracerProvider(
-		trace.WithBatcher(exporter),
-		trace.WithSampler(trace.AlwaysSample()), // Sample all traces for demo purposes; adjust in production
-		trace.WithResource(resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceNameKey.String("WeatherService"),
-		)),
-	)
-	if err != nil {
-		handleErr(err)
-		return
+	if cfg.Exporter == ExporterNoop {
+		// Spans are neither recorded nor exported.
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	} else {
+		var tracerProvider *trace.TracerProvider
+		tracerProvider, err = newTraceProvider(ctx, cfg)
+		if err != nil {
+			handleErr(err)
+			return
+		}
+		shutdownFuncs = append(shutdownFuncs, tracerProvider.Shutdown)
+		otel.SetTracerProvider(tracerProvider)
 	}
-	shutdownFuncs = append(shutdownFuncs, tracerProvider.Shutdown)
-	otel.SetTracerProvider(tracerProvider)
 
 	// Set up meter provider.
 	meterProvider, err := newMeterProvider()
@@ -90,26 +93,52 @@ func SetupOTelSDK(ctx context.Context) (shutdown func(context.Context) error, er
 	return
 }
 
-func newPropagator() propagation.TextMapPropagator {
-	return propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
+func newTraceProvider(ctx context.Context, cfg Config) (*trace.TracerProvider, error) {
+	if cfg.Sampler == nil {
+		cfg.Sampler = trace.AlwaysSample()
+	}
+	traceExporter, err := newTraceExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newTraceProviderWithExporter(cfg, traceExporter), nil
+}
+
+// newTraceProviderWithExporter batches the spans sampled by cfg.Sampler to traceExporter.
+// Spans still in the batch are exported on Shutdown.
+func newTraceProviderWithExporter(cfg Config, traceExporter trace.SpanExporter) *trace.TracerProvider {
+	return trace.NewTracerProvider(
+		trace.WithBatcher(traceExporter),
+		trace.WithSampler(cfg.Sampler),
+		trace.WithResource(newResource(cfg)),
+	)
+}
+
+// newResource describes the service in every span it exports.
+func newResource(cfg Config) *resource.Resource {
+	if cfg.ServiceVersion == "" {
+		cfg.ServiceVersion = Version
+	}
+	return resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceNameKey.String(cfg.ServiceName),
+		semconv.ServiceVersionKey.String(cfg.ServiceVersion),
+		semconv.DeploymentEnvironmentKey.String(cfg.Environment),
+		semconv.ServiceInstanceIDKey.String(cfg.InstanceID),
 	)
 }
 
-func newTraceProvider() (*trace.TracerProvider, error) {
-	traceExporter, err := stdouttrace.New(
-		stdouttrace.WithPrettyPrint())
+func newTraceExporter(ctx context.Context, cfg Config) (trace.SpanExporter, error) {
+	if cfg.Exporter == ExporterStdout {
+		// Print spans as JSON for local development, without a collector.
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	}
+
+	tracesEndpointURL, err := cfg.tracesEndpointURL()
 	if err != nil {
 		return nil, err
 	}
-
-	traceProvider := trace.NewTracerProvider(
-		trace.WithBatcher(traceExporter,
-			// Default is 5s. Set to 1s for demonstrative purposes.
-			trace.WithBatchTimeout(time.Second)),
-	)
-	return traceProvider, nil
+	return otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(tracesEndpointURL))
 }
 
 func newMeterProvider() (*metric.MeterProvider, error) {
@@ -117,15 +146,30 @@ func newMeterProvider() (*metric.MeterProvider, error) {
 	if err != nil {
 		return nil, err
 	}
+	// The Prometheus exporter registers with prometheus.DefaultRegisterer,
+	// served by promhttp.Handler on /metrics.
+	// Metric names already carry their unit, so no unit suffix is added.
+	prometheusExporter, err := prometheus.New(prometheus.WithoutUnits())
+	if err != nil {
+		return nil, err
+	}
 
 	meterProvider := metric.NewMeterProvider(
 		metric.WithReader(metric.NewPeriodicReader(metricExporter,
 			// Default is 1m. Set to 3s for demonstrative purposes.
 			metric.WithInterval(3*time.Second))),
+		metric.WithReader(prometheusExporter),
 	)
 	return meterProvider, nil
 }
 
+// ExemplarFeatureEnv enables the experimental exemplar support of the metric SDK when set to true:
+// the measurements then keep the trace and span IDs of the sampled span they were taken in as exemplars,
+// so a latency spike on a dashboard links to the trace behind it.
+// The metric SDK only reads it from the environment, where main sets it unless it is already set,
+// and OTEL_METRICS_EXEMPLAR_FILTER (default trace_based) selects the measurements kept.
+const ExemplarFeatureEnv = "OTEL_GO_X_EXEMPLAR"
+
 func newLoggerProvider() (*log.LoggerProvider, error) {
 	logExporter, err := stdoutlog.New()
 	if err != nil {
@@ -0,0 +1,106 @@
+package otel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestExemplarCarriesTraceID(t *testing.T) {
+	t.Setenv(ExemplarFeatureEnv, "true")
+
+	reader := metric.NewManualReader()
+	meterProvider := metric.NewMeterProvider(metric.WithReader(reader))
+	histogram, err := meterProvider.Meter("test").Float64Histogram("lookup_duration_ms")
+	if !assert.NoError(t, err) {
+		return
+	}
+	tracerProvider := sdktrace.NewTracerProvider()
+	ctx, span := tracerProvider.Tracer("test").Start(context.Background(), "lookup")
+	histogram.Record(ctx, 42)
+	span.End()
+
+	var rm metricdata.ResourceMetrics
+	if !assert.NoError(t, reader.Collect(context.Background(), &rm)) {
+		return
+	}
+	if !assert.Len(t, rm.ScopeMetrics, 1) || !assert.Len(t, rm.ScopeMetrics[0].Metrics, 1) {
+		return
+	}
+	dataPoints := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64]).DataPoints
+	if !assert.Len(t, dataPoints, 1) || !assert.Len(t, dataPoints[0].Exemplars, 1) {
+		return
+	}
+	exemplar := dataPoints[0].Exemplars[0]
+	traceID := span.SpanContext().TraceID()
+	spanID := span.SpanContext().SpanID()
+	assert.Equal(t, 42.0, exemplar.Value)
+	assert.Equal(t, traceID[:], exemplar.TraceID)
+	assert.Equal(t, spanID[:], exemplar.SpanID)
+}
+
+// keepingExporter keeps the exported spans on Shutdown, which would otherwise reset them.
+type keepingExporter struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keepingExporter) Shutdown(context.Context) error { return nil }
+
+func TestShutdownFlushesSpans(t *testing.T) {
+	exporter := keepingExporter{tracetest.NewInMemoryExporter()}
+	tracerProvider := newTraceProviderWithExporter(Config{Sampler: sdktrace.AlwaysSample()}, exporter)
+	tracer := tracerProvider.Tracer("test")
+	for i := 0; i < 10; i++ {
+		_, span := tracer.Start(context.Background(), "lookup")
+		span.End()
+	}
+	// The spans wait in the batch until it is exported, every 5s by default.
+	assert.Empty(t, exporter.GetSpans())
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+	defer cancel()
+	assert.NoError(t, tracerProvider.Shutdown(ctx))
+	assert.Len(t, exporter.GetSpans(), 10)
+}
+
+func TestGracefulShutdownFlushesPendingSpans(t *testing.T) {
+	exporter := keepingExporter{tracetest.NewInMemoryExporter()}
+	tracerProvider := newTraceProviderWithExporter(Config{Sampler: sdktrace.AlwaysSample()}, exporter)
+	tracer := tracerProvider.Tracer("test")
+	started := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracer.Start(r.Context(), "in-flight")
+		defer span.End()
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+	}))
+	responses := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		responses <- err
+	}()
+	<-started
+
+	// Shut down in the order of main: the server drains the request, ending its span, then the spans are flushed.
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+	defer cancel()
+	srv.Config.Shutdown(ctx)
+	srv.Close()
+	assert.NoError(t, <-responses)
+	assert.NoError(t, tracerProvider.Shutdown(ctx))
+
+	if spans := exporter.GetSpans(); assert.Len(t, spans, 1) {
+		assert.Equal(t, "in-flight", spans[0].Name)
+	}
+}
@@ -0,0 +1,91 @@
+package otel
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// DefaultProfilerInterval is how often a Profiler samples the runtime by default.
+const DefaultProfilerInterval = 30 * time.Second
+
+// Profiler periodically samples goroutine and memory statistics and reports them as gauges.
+// It is a lightweight alternative to the contrib runtime instrumentation.
+type Profiler struct {
+	interval time.Duration
+
+	goroutines atomic.Int64
+	heapAlloc  atomic.Uint64
+	gcCount    atomic.Uint32
+}
+
+// NewProfiler creates a Profiler sampling every interval.
+// A non-positive interval falls back to DefaultProfilerInterval.
+func NewProfiler(interval time.Duration) *Profiler {
+	if interval <= 0 {
+		interval = DefaultProfilerInterval
+	}
+	return &Profiler{interval: interval}
+}
+
+// Start registers the gauges on the global MeterProvider and samples the runtime
+// in a background goroutine until ctx is done.
+func (p *Profiler) Start(ctx context.Context) error {
+	meter := otel.Meter("github.com/leoseiji/go-tracing/otel")
+
+	goroutines, err := meter.Int64ObservableGauge("process.runtime.go.goroutines",
+		metric.WithDescription("Number of goroutines that currently exist."))
+	if err != nil {
+		return err
+	}
+	heapAlloc, err := meter.Int64ObservableGauge("process.runtime.go.mem.heap_alloc_bytes",
+		metric.WithDescription("Bytes of allocated heap objects."), metric.WithUnit("By"))
+	if err != nil {
+		return err
+	}
+	gcCount, err := meter.Int64ObservableGauge("process.runtime.go.gc.count",
+		metric.WithDescription("Number of completed garbage collection cycles."))
+	if err != nil {
+		return err
+	}
+
+	registration, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(goroutines, p.goroutines.Load())
+		o.ObserveInt64(heapAlloc, int64(p.heapAlloc.Load()))
+		o.ObserveInt64(gcCount, int64(p.gcCount.Load()))
+		return nil
+	}, goroutines, heapAlloc, gcCount)
+	if err != nil {
+		return err
+	}
+
+	p.sample()
+	go func() {
+		defer registration.Unregister()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.sample()
+			}
+		}
+	}()
+	return nil
+}
+
+func (p *Profiler) sample() {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	p.goroutines.Store(int64(runtime.NumGoroutine()))
+	p.heapAlloc.Store(memStats.HeapAlloc)
+	p.gcCount.Store(memStats.NumGC)
+}